@@ -1,94 +1,392 @@
 package ahocorasick
 
 import (
+	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"github.com/koron/gelatin/trie"
 )
 
-type Matcher struct {
-	trie *trie.TernaryTrie
+// Matcher is a MatcherOf which has values of any types.
+type Matcher = MatcherOf[any]
+
+// Match is a MatchOf which has a value of any types.
+type Match = MatchOf[any]
+
+// MatcherOf finds patterns in text with Aho-Corasick algorithm.  Each
+// pattern has a value of type V.  Patterns which are added or removed after
+// Compile are applied by compiling again with same options on the next
+// match.  Matching with a matcher which is never compiled reports
+// ErrorNotCompiled or finds nothing.
+//
+// After Compile, a matcher is safe for concurrent use by multiple goroutines
+// as long as patterns are not changed.
+type MatcherOf[V any] struct {
+	trie    *trie.TernaryTrie
+	conf    config
+	entries []entry[V]
+	// index maps patterns to indexes of entries.
+	index map[string]int
+	dup   DuplicatePolicy
+	dfa   *dfa[V]
+	// maxDepth is the longest length of patterns in runes.
+	maxDepth int
+	// bounded is true when any boundaries are configured.
+	bounded bool
+	// lookback is the longest prefix of wildcard patterns before their
+	// anchors in runes.
+	lookback int
+	// globbed is true when any wildcard patterns are added.
+	globbed  bool
+	stats    Stats
+	compiled bool
+	// dirty is true when patterns are changed after Compile.
+	dirty atomic.Bool
+	mu    sync.Mutex
 }
 
-type Match struct {
+// MatchOf represents a match of a pattern in text.  Index and End are byte
+// offsets of the match, text[Index:End] is the matched text.  Other positions
+// are filled only when they are tracked with WithTracking.
+type MatchOf[V any] struct {
 	Index   int
 	Pattern string
-	Value   interface{}
+	Value   V
+	End     int
+	// Groups is the set of groups which the pattern is tagged with.
+	Groups GroupSet
+	// Distance is the edit distance between the pattern and the matched
+	// text, it is filled by MatchApprox.
+	Distance int
+
+	// RuneIndex and RuneEnd are offsets of the match in runes.
+	RuneIndex int
+	RuneEnd   int
+
+	// Line and Column are 1-based line number and column in runes where the
+	// match starts.
+	Line   int
+	Column int
 }
 
-type nodeData struct {
+type entry[V any] struct {
+	pattern string
+	value   V
+	patternConfig
+}
+
+// patternConfig keeps configurations for each pattern.
+type patternConfig struct {
+	boundary Boundary
+	groups   GroupSet
+	wildcard bool
+}
+
+// PatternOption configures a pattern on Add.
+type PatternOption func(*patternConfig)
+
+type nodeData[V any] struct {
 	pattern *string
-	offset  int
-	value   interface{}
+	value   V
 	failure *trie.TernaryNode
+	// id is sequential number of Add, it is used as priority.
+	id       int
+	boundary Boundary
+	groups   GroupSet
+	// dups keeps data of other patterns which have same key.
+	dups []*nodeData[V]
+	// depth is length of the node's label sequence in runes.
+	depth int
+	// glob is the parsed wildcard pattern, depth is length of its anchor.
+	glob *glob
+	// globs keeps data of wildcard patterns which are anchored at the node.
+	globs []*nodeData[V]
 }
 
+// New creates a Matcher which has values of any types.
 func New() *Matcher {
-	return &Matcher{
-		trie: trie.NewTernaryTrie(),
+	return NewOf[any]()
+}
+
+// NewOf creates a MatcherOf which has values of type V.
+func NewOf[V any]() *MatcherOf[V] {
+	return &MatcherOf[V]{
+		trie:  trie.NewTernaryTrie(),
+		index: map[string]int{},
 	}
 }
 
-func (m *Matcher) Add(pattern string, v interface{}) {
-	_, n := utf8.DecodeLastRuneInString(pattern)
-	m.trie.Put(pattern, &nodeData{
-		pattern: &pattern,
-		offset:  len(pattern) - n,
-		value:   v,
-	})
+// DuplicatePolicy specifies how to treat a pattern which is added already.
+type DuplicatePolicy int
+
+const (
+	// Overwrite replaces the value of the pattern.
+	Overwrite DuplicatePolicy = iota
+
+	// KeepFirst ignores values which are added later.
+	KeepFirst
+
+	// Append keeps all values for the pattern, they are reported as
+	// separate matches in order of Add.
+	Append
+
+	// Reject makes Add return ErrorDuplicatedPattern.
+	Reject
+)
+
+// SetDuplicatePolicy sets policy for patterns which are added twice or more.
+// It is also applied on Compile to different patterns which are same after
+// folding.  The default is Overwrite.
+func (m *MatcherOf[V]) SetDuplicatePolicy(p DuplicatePolicy) {
+	m.dup = p
+}
+
+// Add adds a pattern with a value.  It returns ErrorDuplicatedPattern when
+// the pattern is added already with Reject policy.
+func (m *MatcherOf[V]) Add(pattern string, v V, opts ...PatternOption) error {
+	e := entry[V]{pattern: pattern, value: v}
+	for _, o := range opts {
+		o(&e.patternConfig)
+	}
+	if i, ok := m.index[pattern]; ok {
+		switch m.dup {
+		case Overwrite:
+			m.entries[i] = e
+			m.dirty.Store(true)
+			return nil
+		case KeepFirst:
+			return nil
+		case Reject:
+			return &ErrorDuplicatedPattern{Pattern: pattern}
+		}
+	} else {
+		m.index[pattern] = len(m.entries)
+	}
+	m.entries = append(m.entries, e)
+	m.dirty.Store(true)
+	return nil
+}
+
+// Remove removes all values for pattern.  It returns false when pattern is
+// not found.
+func (m *MatcherOf[V]) Remove(pattern string) bool {
+	n := 0
+	for _, e := range m.entries {
+		if e.pattern != pattern {
+			m.entries[n] = e
+			n++
+		}
+	}
+	if n == len(m.entries) {
+		return false
+	}
+	clear(m.entries[n:])
+	m.entries = m.entries[:n]
+	m.reindex()
+	m.dirty.Store(true)
+	return true
+}
+
+func (m *MatcherOf[V]) reindex() {
+	m.index = make(map[string]int, len(m.entries))
+	for i, e := range m.entries {
+		if _, ok := m.index[e.pattern]; !ok {
+			m.index[e.pattern] = i
+		}
+	}
+}
+
+// Stats is statistics of a compiled automaton.
+type Stats struct {
+	// Patterns is the number of patterns.
+	Patterns int
+	// States is the number of states including the root.
+	States int
+	// MaxDepth is the longest length of patterns in runes, or bytes in byte
+	// mode.
+	MaxDepth int
+	// TableBytes is the size of the transition table of the DFA in bytes, it
+	// is 0 without WithDFA.
+	TableBytes int
 }
 
-func (m *Matcher) Compile() error {
+// Compile builds the automaton with patterns.  It returns
+// ErrorInvalidPattern when a pattern is empty, not valid UTF-8 without
+// WithBytes, or an invalid wildcard pattern.
+func (m *MatcherOf[V]) Compile(opts ...Option) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conf = newConfig(opts)
+	m.compiled = true
+	m.dirty.Store(true)
+	return m.compile()
+}
+
+// Stats returns statistics of the automaton which is compiled last.
+func (m *MatcherOf[V]) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// prepare compiles the matcher again when patterns are changed after
+// Compile.
+func (m *MatcherOf[V]) prepare() error {
+	if !m.dirty.Load() {
+		if !m.compiled {
+			return ErrorNotCompiled
+		}
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.compiled {
+		return ErrorNotCompiled
+	}
+	if m.dirty.Load() {
+		return m.compile()
+	}
+	return nil
+}
+
+func (m *MatcherOf[V]) compile() error {
+	m.trie = trie.NewTernaryTrie()
+	m.maxDepth = 0
+	m.lookback = 0
+	m.bounded = m.conf.boundary != nil
+	m.globbed = false
+	for i := range m.entries {
+		e := &m.entries[i]
+		if err := m.conf.validate(e.pattern); err != nil {
+			return &ErrorInvalidPattern{Pattern: e.pattern, Err: err}
+		}
+		d := &nodeData[V]{
+			pattern:  &e.pattern,
+			value:    e.value,
+			id:       i,
+			boundary: e.boundary,
+			groups:   e.groups,
+		}
+		if e.wildcard {
+			g, err := parseGlob(e.pattern, &m.conf)
+			if err != nil {
+				return &ErrorInvalidPattern{Pattern: e.pattern, Err: err}
+			}
+			d.glob = g
+			m.addGlob(d)
+		} else if err := m.addLiteral(m.conf.key(e.pattern), d); err != nil {
+			return err
+		}
+		if e.boundary != nil {
+			m.bounded = true
+		}
+	}
 	m.trie.Balance()
 	root := m.trie.Root().(*trie.TernaryNode)
-	root.SetValue(&nodeData{failure: root})
+	root.SetValue(&nodeData[V]{failure: root})
+	states := 0
 	// fill data.failure of each node.
 	trie.EachWidth(m.trie, func(n trie.Node) bool {
+		states++
 		parent := n.(*trie.TernaryNode)
 		parent.Each(func(m trie.Node) bool {
-			fillFailure(m.(*trie.TernaryNode), root, parent)
+			fillFailure[V](m.(*trie.TernaryNode), root, parent)
 			return true
 		})
 		return true
 	})
+	m.dfa = nil
+	if m.conf.dfa {
+		m.dfa = newDFA[V](m.trie)
+	}
+	m.stats = Stats{Patterns: len(m.entries), States: states, MaxDepth: m.maxDepth}
+	if m.dfa != nil {
+		m.stats.TableBytes = m.dfa.tableBytes()
+	}
+	m.dirty.Store(false)
 	return nil
 }
 
-func fillFailure(curr, root, parent *trie.TernaryNode) {
-	data := getNodeData(curr)
+// addLiteral puts data of a literal pattern at key, or adds it to data of
+// the same key with the duplicate policy.
+func (m *MatcherOf[V]) addLiteral(key string, d *nodeData[V]) error {
+	if n := m.trie.Get(key); n != nil && n.Value() != nil {
+		old := n.Value().(*nodeData[V])
+		if old.pattern != nil {
+			switch m.dup {
+			case KeepFirst:
+				return nil
+			case Append:
+				old.dups = append(old.dups, d)
+				return nil
+			case Reject:
+				return &ErrorDuplicatedPattern{Pattern: *d.pattern}
+			}
+		}
+		d.globs = old.globs
+	}
+	m.trie.Put(key, d)
+	if n := utf8.RuneCountInString(key); n > m.maxDepth {
+		m.maxDepth = n
+	}
+	return nil
+}
+
+// addGlob adds data of a wildcard pattern to the node of its anchor.
+func (m *MatcherOf[V]) addGlob(d *nodeData[V]) {
+	g := d.glob
+	d.depth = utf8.RuneCountInString(g.anchor)
+	holder := &nodeData[V]{}
+	if n := m.trie.Get(g.anchor); n != nil && n.Value() != nil {
+		holder = n.Value().(*nodeData[V])
+	} else {
+		m.trie.Put(g.anchor, holder)
+	}
+	holder.globs = append(holder.globs, d)
+	m.globbed = true
+	m.maxDepth = max(m.maxDepth, g.maxLen)
+	m.lookback = max(m.lookback, g.lookback())
+}
+
+func fillFailure[V any](curr, root, parent *trie.TernaryNode) {
+	data := getNodeData[V](curr)
 	if data == nil {
-		data = &nodeData{}
+		data = &nodeData[V]{}
 		curr.SetValue(data)
 	}
+	data.depth = getNodeData[V](parent).depth + 1
 	if parent == root {
 		data.failure = root
 		return
 	}
 	// Determine failure node.
-	fnode := getNextNode(getNodeFailure(parent, root), root, curr.Label())
+	fnode := getNextNode[V](getNodeFailure[V](parent, root), root, curr.Label())
 	data.failure = fnode
 }
 
-func (m *Matcher) Match(text string) <-chan Match {
-	ch := make(chan Match, 1)
-	go m.startMatch(text, ch)
-	return ch
+// Match returns a channel which receives matches in text.  The channel is
+// closed after all matches are sent.  It returns ErrorNotCompiled or an
+// error of compiling changed patterns before scanning.
+func (m *MatcherOf[V]) Match(text string) (<-chan MatchOf[V], error) {
+	s, err := m.newScanner()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan MatchOf[V], 1)
+	go startMatch(s, text, ch)
+	return ch, nil
 }
 
-func (m *Matcher) startMatch(text string, ch chan<- Match) {
+func startMatch[V any](s *scanner[V], text string, ch chan<- MatchOf[V]) {
 	defer close(ch)
-	root := m.trie.Root().(*trie.TernaryNode)
-	curr := root
-	for i, r := range text {
-		curr = getNextNode(curr, root, r)
-		if curr == root {
-			continue
-		}
-		fireAll(curr, root, ch, i)
-	}
+	scanString(s, text, func(v MatchOf[V]) bool {
+		ch <- v
+		return true
+	})
 }
 
-func getNextNode(node, root *trie.TernaryNode, r rune) *trie.TernaryNode {
+func getNextNode[V any](node, root *trie.TernaryNode, r rune) *trie.TernaryNode {
 	for {
 		next, _ := node.Get(r).(*trie.TernaryNode)
 		if next != nil {
@@ -96,31 +394,30 @@ func getNextNode(node, root *trie.TernaryNode, r rune) *trie.TernaryNode {
 		} else if node == root {
 			return root
 		}
-		node = getNodeFailure(node, root)
+		node = getNodeFailure[V](node, root)
 	}
 }
 
-func fireAll(curr, root *trie.TernaryNode, ch chan<- Match, idx int) {
+func fireAll[V any](curr, root *trie.TernaryNode, proc func(*nodeData[V]) bool) bool {
 	for curr != root {
-		data := getNodeData(curr)
-		if data.pattern != nil {
-			ch <- Match{
-				Index:   idx - data.offset,
-				Pattern: *data.pattern,
-				Value:   data.value,
+		data := getNodeData[V](curr)
+		if data.pattern != nil || len(data.globs) > 0 {
+			if !proc(data) {
+				return false
 			}
 		}
 		curr = data.failure
 	}
+	return true
 }
 
-func getNodeData(node *trie.TernaryNode) *nodeData {
-	d, _ := node.Value().(*nodeData)
+func getNodeData[V any](node *trie.TernaryNode) *nodeData[V] {
+	d, _ := node.Value().(*nodeData[V])
 	return d
 }
 
-func getNodeFailure(node, root *trie.TernaryNode) *trie.TernaryNode {
-	next := getNodeData(node).failure
+func getNodeFailure[V any](node, root *trie.TernaryNode) *trie.TernaryNode {
+	next := getNodeData[V](node).failure
 	if next == nil {
 		return root
 	}
@@ -1,18 +1,20 @@
 package ahocorasick
 
 import (
+	"context"
+	"errors"
 	"github.com/koron/gelatin/trie"
 	"testing"
 )
 
-func checkNode(t *testing.T, node trie.Node, size int, data nodeData) {
+func checkNode(t *testing.T, node trie.Node, size int, data nodeData[any]) {
 	if node == nil {
 		t.Error("Nil node:", data)
 	}
 	if node.Size() != size {
 		t.Errorf("Unexpected childrens: %d != %d", node.Size(), size)
 	}
-	d := node.Value().(*nodeData)
+	d := node.Value().(*nodeData[any])
 	if d == nil {
 		t.Error("Nil data:", data, node)
 	}
@@ -30,20 +32,30 @@ func checkNode(t *testing.T, node trie.Node, size int, data nodeData) {
 	}
 }
 
-func invalidData(failure trie.Node) nodeData {
-	return nodeData{
+func invalidData(failure trie.Node) nodeData[any] {
+	return nodeData[any]{
 		failure: failure.(*trie.TernaryNode),
 	}
 }
 
-func validData(pattern string, value interface{}, failure trie.Node) nodeData {
-	return nodeData{
+func validData(pattern string, value interface{}, failure trie.Node) nodeData[any] {
+	return nodeData[any]{
 		pattern: &pattern,
 		value:   value,
 		failure: failure.(*trie.TernaryNode),
 	}
 }
 
+// matchAll returns all matches in text, it fails t on errors.
+func matchAll[V any](t *testing.T, m *MatcherOf[V], text string) []MatchOf[V] {
+	t.Helper()
+	all, err := MatchAll(m, text)
+	if err != nil {
+		t.Fatal("MatchAll failed:", err)
+	}
+	return all
+}
+
 func newTestMatcher() *Matcher {
 	m := New()
 	m.Add("ab", 2)
@@ -56,7 +68,10 @@ func newTestMatcher() *Matcher {
 }
 
 func TestTree(t *testing.T) {
-	m := newTestMatcher()
+	checkTree(t, newTestMatcher())
+}
+
+func checkTree(t *testing.T, m *Matcher) {
 	// Check tree structure.
 	r := m.trie.Root()
 	checkNode(t, r, 3, invalidData(r))
@@ -113,11 +128,160 @@ func assertMatches(t *testing.T, exp, act []Match) {
 func TestResults(t *testing.T) {
 	m := newTestMatcher()
 
-	r1 := MatchAll(m, "abcde")
+	r1 := matchAll(t, m, "abcde")
 	assertMatches(t, []Match{
-		Match{0, "ab", 2},
-		Match{1, "bc", 4},
-		Match{3, "d", 7},
-		Match{0, "abcde", 10},
+		Match{Index: 0, Pattern: "ab", Value: 2},
+		Match{Index: 1, Pattern: "bc", Value: 4},
+		Match{Index: 3, Pattern: "d", Value: 7},
+		Match{Index: 0, Pattern: "abcde", Value: 10},
 	}, r1)
 }
+
+func TestAddAfterCompile(t *testing.T) {
+	m := newTestMatcher()
+	m.Compile(WithMode(LeftmostLongest))
+	m.Add("cde", 11)
+	m.Add("xabc", 12)
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "xabc", Value: 12},
+		{Index: 4, Pattern: "d", Value: 7},
+		{Index: 6, Pattern: "abcde", Value: 10},
+		{Index: 12, Pattern: "cde", Value: 11},
+	}, matchAll(t, m, "xabcd abcde cde"))
+	if m.conf.mode != LeftmostLongest {
+		t.Error("options are not kept on recompile")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := newTestMatcher()
+	if !m.Remove("abcde") {
+		t.Error("Remove returns false for existing pattern")
+	}
+	if m.Remove("abcde") {
+		t.Error("Remove returns true for removed pattern")
+	}
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ab", Value: 2},
+		{Index: 1, Pattern: "bc", Value: 4},
+		{Index: 3, Pattern: "d", Value: 7},
+	}, matchAll(t, m, "abcde"))
+}
+
+func TestNotCompiled(t *testing.T) {
+	m := New()
+	m.Add("ab", 1)
+	if err := m.Each("ab", func(Match) bool { return true }); err != ErrorNotCompiled {
+		t.Errorf("Each returns unexpected error: %v", err)
+	}
+	assertNotCompiled(t, m, ErrorNotCompiled)
+	m.Compile()
+	assertMatches(t, []Match{{Index: 0, Pattern: "ab", Value: 1}}, matchAll(t, m, "ab"))
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		err     error
+	}{
+		{"", ErrorEmptyPattern},
+		{"a\xffb", ErrorInvalidUTF8},
+	} {
+		m := New()
+		m.Add("ab", 1)
+		m.Add(c.pattern, 2)
+		err := m.Compile()
+		var perr *ErrorInvalidPattern
+		if !errors.As(err, &perr) || perr.Pattern != c.pattern || !errors.Is(err, c.err) {
+			t.Errorf("Compile with %q returns unexpected error: %v", c.pattern, err)
+		}
+		if err := m.Each("ab", func(Match) bool { return true }); !errors.Is(err, c.err) {
+			t.Errorf("Each returns unexpected error: %v", err)
+		}
+		assertNotCompiled(t, m, c.err)
+		m.Remove(c.pattern)
+		assertMatches(t, []Match{{Index: 0, Pattern: "ab", Value: 1}}, matchAll(t, m, "ab"))
+
+		// invalid patterns which are added after Compile are reported too.
+		m.Add(c.pattern, 2)
+		assertNotCompiled(t, m, c.err)
+	}
+}
+
+// assertNotCompiled checks entry points of matching report err.
+func assertNotCompiled(t *testing.T, m *Matcher, err error) {
+	t.Helper()
+	if _, e := MatchAll(m, "ab"); !errors.Is(e, err) {
+		t.Errorf("MatchAll returns unexpected error: %v", e)
+	}
+	if _, e := m.Match("ab"); !errors.Is(e, err) {
+		t.Errorf("Match returns unexpected error: %v", e)
+	}
+	if _, e := m.MatchContext(context.Background(), "ab"); !errors.Is(e, err) {
+		t.Errorf("MatchContext returns unexpected error: %v", e)
+	}
+	if _, e := m.All("ab"); !errors.Is(e, err) {
+		t.Errorf("All returns unexpected error: %v", e)
+	}
+	if _, e := m.ReplaceAll("ab"); !errors.Is(e, err) {
+		t.Errorf("ReplaceAll returns unexpected error: %v", e)
+	}
+}
+
+func TestStats(t *testing.T) {
+	m := newTestMatcher()
+	if s, exp := m.Stats(), (Stats{Patterns: 5, States: 11, MaxDepth: 5}); s != exp {
+		t.Errorf("Stats returns %+v, expected %+v", s, exp)
+	}
+	m.Add("x", 1)
+	m.Compile(WithDFA())
+	if s, exp := m.Stats(), (Stats{Patterns: 6, States: 12, MaxDepth: 5, TableBytes: 12 * 7 * 4}); s != exp {
+		t.Errorf("Stats returns %+v, expected %+v", s, exp)
+	}
+}
+
+func TestMatcherOf(t *testing.T) {
+	type entity struct {
+		ID   int
+		Kind string
+	}
+	m := NewOf[entity]()
+	m.Add("Go", entity{1, "lang"})
+	m.Add("Gopher", entity{2, "animal"})
+	m.Compile(WithMode(LeftmostLongest))
+	var kinds []string
+	seq, err := m.All("Go, Gopher")
+	if err != nil {
+		t.Fatal("All failed:", err)
+	}
+	for v := range seq {
+		kinds = append(kinds, v.Value.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != "lang" || kinds[1] != "animal" {
+		t.Errorf("unexpected kinds: %v", kinds)
+	}
+
+	m2 := NewOf[int]()
+	m2.Add("ab", 2)
+	m2.Add("bc", 4)
+	m2.Compile()
+	data, err := m2.MarshalBinary()
+	if err != nil {
+		t.Fatal("MarshalBinary failed:", err)
+	}
+	m3 := NewOf[int]()
+	if err := m3.UnmarshalBinary(data); err != nil {
+		t.Fatal("UnmarshalBinary failed:", err)
+	}
+	sum := 0
+	m3.Each("abc", func(v MatchOf[int]) bool {
+		sum += v.Value
+		return true
+	})
+	if sum != 6 {
+		t.Errorf("unexpected sum of values: %d", sum)
+	}
+	if err := NewOf[string]().UnmarshalBinary(data); err != ErrorValueType {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package ahocorasick
+
+import "unicode/utf8"
+
+// Anchor specifies where matches of anchored matching should be.
+type Anchor int
+
+const (
+	// AnchorStart matches patterns at the start of text.
+	AnchorStart Anchor = iota
+
+	// AnchorEnd matches patterns at the end of text.
+	AnchorEnd
+
+	// AnchorFull matches patterns which equal whole text.
+	AnchorFull
+)
+
+// EachAnchored calls proc for each match which is anchored by a in text.
+// Matches are reported in order of their ends including overlapping ones,
+// regardless of the match mode.  Scanning stops as soon as no more anchored
+// matches can be found: AnchorStart and AnchorFull stop when no patterns
+// can start at the start of text, and AnchorEnd scans only the tail of text
+// which the longest pattern can cover.  It also stops when proc returns
+// false.
+func (m *MatcherOf[V]) EachAnchored(text string, a Anchor, proc func(MatchOf[V]) bool) error {
+	s, err := m.newScanner()
+	if err != nil {
+		return err
+	}
+	s.mode = Standard
+	filter := func(v MatchOf[V]) bool {
+		if a != AnchorEnd && v.Index != 0 || a != AnchorStart && v.End != len(text) {
+			return true
+		}
+		return proc(v)
+	}
+	i := 0
+	if a == AnchorEnd {
+		// a match may have runes twice as many as maxDepth before folding,
+		// and one more rune is needed for folding and boundaries.
+		i = tailStart(s.bytes, text, 2*m.maxDepth+2)
+		if s.track != 0 {
+			for j := 0; j < i; {
+				r, n := decodeString(s.bytes, text[j:])
+				s.cursor = s.cursor.next(r)
+				j += n
+			}
+		}
+	}
+	for i < len(text) {
+		r, n := decodeString(s.bytes, text[i:])
+		if !s.step(r, i, n, filter) {
+			return nil
+		}
+		i += n
+		if a != AnchorEnd && s.pos() > 0 {
+			return nil
+		}
+	}
+	s.finish(filter)
+	return nil
+}
+
+// Anchored checks any patterns match text at the place which is specified
+// by a.
+func (m *MatcherOf[V]) Anchored(text string, a Anchor) (bool, error) {
+	found := false
+	err := m.EachAnchored(text, a, func(MatchOf[V]) bool {
+		found = true
+		return false
+	})
+	return found, err
+}
+
+// tailStart returns the start index of the last n runes in text.
+func tailStart(bytes bool, text string, n int) int {
+	if bytes {
+		return max(len(text)-n, 0)
+	}
+	i := len(text)
+	for ; n > 0 && i > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:i])
+		i -= size
+	}
+	return i
+}
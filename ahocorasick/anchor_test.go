@@ -0,0 +1,116 @@
+package ahocorasick
+
+import "testing"
+
+func eachAnchored(t *testing.T, m *Matcher, text string, a Anchor) []Match {
+	t.Helper()
+	var all []Match
+	if err := m.EachAnchored(text, a, func(v Match) bool {
+		all = append(all, v)
+		return true
+	}); err != nil {
+		t.Fatal("EachAnchored failed:", err)
+	}
+	return all
+}
+
+func TestEachAnchored(t *testing.T) {
+	m := New()
+	m.Add("/api", 0)
+	m.Add("/api/v1", 1)
+	m.Add("v1", 2)
+	m.Add(".json", 3)
+	m.Compile(WithMode(LeftmostLongest))
+
+	text := "/api/v1/users.json"
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "/api", Value: 0},
+		{Index: 0, Pattern: "/api/v1", Value: 1},
+	}, eachAnchored(t, m, text, AnchorStart))
+	assertMatches(t, []Match{
+		{Index: 13, Pattern: ".json", Value: 3},
+	}, eachAnchored(t, m, text, AnchorEnd))
+	assertMatches(t, nil, eachAnchored(t, m, text, AnchorFull))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "/api/v1", Value: 1},
+	}, eachAnchored(t, m, "/api/v1", AnchorFull))
+	assertMatches(t, nil, eachAnchored(t, m, "x/api/v1", AnchorStart))
+	assertMatches(t, nil, eachAnchored(t, m, "/api/v1x", AnchorEnd))
+}
+
+func TestEachAnchoredStop(t *testing.T) {
+	m := New()
+	m.Add("ab", 0)
+	m.Compile()
+	// scanning stops before invalid positions are reported.
+	var ends []int
+	m.EachAnchored("abxab", AnchorStart, func(v Match) bool {
+		ends = append(ends, v.End)
+		return true
+	})
+	if len(ends) != 1 || ends[0] != 2 {
+		t.Errorf("unexpected ends: %v", ends)
+	}
+
+	// pos is kept in Standard mode, it is the start of the current node.
+	s, _ := m.newScanner()
+	s.mode = Standard
+	scanString(s, "xab", func(Match) bool { return true })
+	if p := s.pos(); p != 1 {
+		t.Errorf("unexpected pos: %d", p)
+	}
+}
+
+func TestEachAnchoredOptions(t *testing.T) {
+	m := New()
+	m.Add("ｶﾞｲﾄﾞ", 0)
+	m.Add("one", 1, Bounded(WordBoundary))
+	m.Add("ID-??", 2, Wildcard())
+	m.Compile(WithFold(FoldWidth|FoldCase), WithTracking(TrackRunes|TrackLines))
+
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ｶﾞｲﾄﾞ", Value: 0},
+	}, eachAnchored(t, m, "ガイドです", AnchorStart))
+	assertMatches(t, nil, eachAnchored(t, m, "ONEs", AnchorStart))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "one", Value: 1},
+	}, eachAnchored(t, m, "ONE two", AnchorStart))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ID-??", Value: 2},
+	}, eachAnchored(t, m, "id-42", AnchorFull))
+
+	text := "a very long line\nwith a tail id-42"
+	act := eachAnchored(t, m, text, AnchorEnd)
+	assertPositions(t, []Match{
+		{Index: 29, Pattern: "ID-??", Value: 2, End: 34, RuneIndex: 29, RuneEnd: 34, Line: 2, Column: 13},
+	}, act)
+}
+
+func TestAnchored(t *testing.T) {
+	m := New()
+	m.Add("GET ", nil)
+	m.Add("POST ", nil)
+	m.Compile()
+	for _, tc := range []struct {
+		text string
+		a    Anchor
+		exp  bool
+	}{
+		{"GET /", AnchorStart, true},
+		{"PUT /", AnchorStart, false},
+		{"xPOST ", AnchorEnd, true},
+		{"GET ", AnchorFull, true},
+		{"GET  ", AnchorFull, false},
+	} {
+		ok, err := m.Anchored(tc.text, tc.a)
+		if err != nil {
+			t.Fatal("Anchored failed:", err)
+		}
+		if ok != tc.exp {
+			t.Errorf("Anchored(%q, %d) = %t", tc.text, tc.a, ok)
+		}
+	}
+	if _, err := New().Anchored("", AnchorStart); err != ErrorNotCompiled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package ahocorasick
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/koron/gelatin/trie"
+)
+
+// MatchApprox finds approximate occurrences of patterns in text, which are
+// within k substitutions, insertions and deletions of runes.  The distance
+// of a match is less than the length of its pattern, so at least one rune
+// matches.  For each pattern, the closest one is reported among occurrences
+// which overlap each other.  Matches are returned in order of Index, and
+// only Index, End and Distance are filled as positions.  Wildcard patterns
+// are not matched approximately, they are excluded from the results.
+//
+// It walks the trie of the matcher from each rune of text with rows of edit
+// distances, so it takes time proportional to k and the size of the trie.
+func (m *MatcherOf[V]) MatchApprox(text string, k int) ([]MatchOf[V], error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	if k < 0 {
+		k = 0
+	}
+	a := &approx[V]{
+		text:  text,
+		bytes: m.conf.bytes,
+		runes: m.foldText(text),
+		k:     k,
+		rows:  make([][]int, m.maxDepth+1),
+		conf:  &m.conf,
+	}
+	root := m.trie.Root().(*trie.TernaryNode)
+	for i := range a.runes {
+		a.span = a.runes[i:min(len(a.runes), i+m.maxDepth+k)]
+		for d := range a.rows {
+			if cap(a.rows[d]) < len(a.span)+1 {
+				a.rows[d] = make([]int, len(a.span)+1)
+			}
+			a.rows[d] = a.rows[d][:len(a.span)+1]
+		}
+		for j := range a.rows[0] {
+			a.rows[0][j] = j
+		}
+		a.walk(root, 0)
+	}
+	return a.results(), nil
+}
+
+// foldText returns folded runes of text with their ranges.
+func (m *MatcherOf[V]) foldText(text string) []textRune {
+	var runes []textRune
+	out := func(r rune, start, end int) bool {
+		runes = append(runes, textRune{r: r, start: start, end: end})
+		return true
+	}
+	var f *folder
+	if m.conf.fold != 0 && !m.conf.bytes {
+		f = &folder{fold: m.conf.fold}
+	}
+	for i := 0; i < len(text); {
+		r, n := decodeString(m.conf.bytes, text[i:])
+		if f != nil {
+			f.feed(r, i, i+n, out)
+		} else {
+			out(r, i, i+n)
+		}
+		i += n
+	}
+	if f != nil {
+		f.flush(out)
+	}
+	return runes
+}
+
+type approx[V any] struct {
+	text  string
+	bytes bool
+	runes []textRune
+	k     int
+	conf  *config
+	// span is runes which may be matched from the current start.
+	span []textRune
+	// rows keeps edit distances between labels of nodes in the path and
+	// each prefix of span.
+	rows [][]int
+	// cands keeps the closest occurrences for each start.
+	cands []approxCandidate[V]
+}
+
+type approxCandidate[V any] struct {
+	data       *nodeData[V]
+	start, end int
+	distance   int
+}
+
+func (a *approx[V]) walk(n *trie.TernaryNode, depth int) {
+	n.Each(func(c trie.Node) bool {
+		child := c.(*trie.TernaryNode)
+		prev, row := a.rows[depth], a.rows[depth+1]
+		row[0] = prev[0] + 1
+		lowest := row[0]
+		for j := 1; j < len(row); j++ {
+			cost := 1
+			if a.span[j-1].r == child.Label() {
+				cost = 0
+			}
+			row[j] = min(prev[j]+1, row[j-1]+1, prev[j-1]+cost)
+			lowest = min(lowest, row[j])
+		}
+		if lowest > a.k {
+			return true
+		}
+		if d := getNodeData[V](child); d.pattern != nil {
+			a.check(d, depth+1, row)
+		}
+		a.walk(child, depth+1)
+		return true
+	})
+}
+
+// check keeps an occurrence of d which is the closest in row.  When two or
+// more are closest, the one which has the nearest length to the pattern is
+// chosen.
+func (a *approx[V]) check(d *nodeData[V], length int, row []int) {
+	best := 0
+	for j := 1; j < len(row); j++ {
+		if best == 0 || row[j] < row[best] ||
+			row[j] == row[best] && abs(j-length) < abs(best-length) {
+			best = j
+		}
+	}
+	if best == 0 || row[best] > a.k || row[best] >= length {
+		return
+	}
+	start, end := a.span[0].start, a.span[best-1].end
+	if b := d.boundary; b != nil || a.conf.boundary != nil {
+		if b == nil {
+			b = a.conf.boundary
+		}
+		if !b(a.runeBefore(start), a.runeAfter(end)) {
+			return
+		}
+	}
+	a.cands = append(a.cands, approxCandidate[V]{
+		data:     d,
+		start:    start,
+		end:      end,
+		distance: row[best],
+	})
+}
+
+func (a *approx[V]) runeBefore(idx int) rune {
+	if idx == 0 {
+		return NoRune
+	}
+	if a.bytes {
+		return rune(a.text[idx-1])
+	}
+	r, _ := utf8.DecodeLastRuneInString(a.text[:idx])
+	return r
+}
+
+func (a *approx[V]) runeAfter(idx int) rune {
+	if idx >= len(a.text) {
+		return NoRune
+	}
+	r, _ := decodeString(a.bytes, a.text[idx:])
+	return r
+}
+
+// results chooses the closest one among overlapping occurrences for each
+// pattern, and returns them as matches.
+func (a *approx[V]) results() []MatchOf[V] {
+	sort.SliceStable(a.cands, func(i, j int) bool {
+		x, y := a.cands[i], a.cands[j]
+		if x.distance != y.distance {
+			return x.distance < y.distance
+		}
+		return x.start < y.start
+	})
+	accepted := map[*nodeData[V]][]approxCandidate[V]{}
+	var chosen []approxCandidate[V]
+	for _, c := range a.cands {
+		ok := true
+		for _, x := range accepted[c.data] {
+			if c.start < x.end && x.start < c.end {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			accepted[c.data] = append(accepted[c.data], c)
+			chosen = append(chosen, c)
+		}
+	}
+	sort.Slice(chosen, func(i, j int) bool {
+		x, y := chosen[i], chosen[j]
+		if x.start != y.start {
+			return x.start < y.start
+		}
+		if x.end != y.end {
+			return x.end < y.end
+		}
+		return x.data.id < y.data.id
+	})
+	var matches []MatchOf[V]
+	for _, c := range chosen {
+		for i := -1; i < len(c.data.dups); i++ {
+			d := c.data
+			if i >= 0 {
+				d = c.data.dups[i]
+			}
+			matches = append(matches, MatchOf[V]{
+				Index:    c.start,
+				Pattern:  *d.pattern,
+				Value:    d.value,
+				End:      c.end,
+				Groups:   d.groups,
+				Distance: c.distance,
+			})
+		}
+	}
+	return matches
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+package ahocorasick
+
+import (
+	"testing"
+)
+
+func newApproxMatcher(opts ...Option) *Matcher {
+	m := New()
+	m.Add("gopher", 1)
+	m.Add("kubernetes", 2)
+	m.Add("cat", 3)
+	m.Compile(opts...)
+	return m
+}
+
+func matchApprox(t *testing.T, m *Matcher, text string, k int) []Match {
+	t.Helper()
+	matches, err := m.MatchApprox(text, k)
+	if err != nil {
+		t.Fatal("MatchApprox failed:", err)
+	}
+	return matches
+}
+
+func TestMatchApprox(t *testing.T) {
+	m := newApproxMatcher()
+	text := "a gophr, kubernetess and cst"
+	assertPositions(t, []Match{
+		{Index: 2, Pattern: "gopher", Value: 1, End: 7, Distance: 1},
+		{Index: 9, Pattern: "kubernetes", Value: 2, End: 19, Distance: 0},
+		{Index: 25, Pattern: "cat", Value: 3, End: 28, Distance: 1},
+	}, matchApprox(t, m, text, 1))
+	assertPositions(t, []Match{
+		{Index: 9, Pattern: "kubernetes", Value: 2, End: 19, Distance: 0},
+	}, matchApprox(t, m, text, 0))
+
+	assertPositions(t, []Match{
+		{Index: 4, Pattern: "cat", Value: 3, End: 7, Distance: 0},
+		{Index: 8, Pattern: "cat", Value: 3, End: 11, Distance: 1},
+		{Index: 19, Pattern: "cat", Value: 3, End: 22, Distance: 1},
+	}, matchApprox(t, m, "the cat sat on the mat", 1))
+}
+
+func TestMatchApproxOptions(t *testing.T) {
+	m := newApproxMatcher(WithFold(FoldCase|FoldWidth), WithBoundary(WordBoundary))
+	assertPositions(t, []Match{
+		{Index: 0, Pattern: "gopher", Value: 1, End: 15, Distance: 1},
+		{Index: 24, Pattern: "cat", Value: 3, End: 27, Distance: 1},
+	}, matchApprox(t, m, "ＧｏＰｈｒ concat, CAB", 1))
+}
+
+func TestMatchApproxNotCompiled(t *testing.T) {
+	m := New()
+	m.Add("cat", 1)
+	if _, err := m.MatchApprox("cat", 1); err != ErrorNotCompiled {
+		t.Errorf("MatchApprox should fail with ErrorNotCompiled: %v", err)
+	}
+}
+
+func TestMatchApproxWildcard(t *testing.T) {
+	m := New()
+	m.Add("cat", 1)
+	m.Add("ca?", 2, Wildcard())
+	m.Compile()
+	assertPositions(t, []Match{
+		{Index: 0, Pattern: "cat", Value: 1, End: 3, Distance: 1},
+	}, matchApprox(t, m, "cab", 1))
+}
@@ -0,0 +1,68 @@
+package ahocorasick
+
+import "unicode"
+
+// NoRune is passed to Boundary instead of a rune at start or end of text.
+const NoRune rune = -1
+
+// Boundary checks runes around a match and reports whether the match is
+// acceptable.  prev is a rune just before the match and next is a rune just
+// after it.
+type Boundary func(prev, next rune) bool
+
+// WithBoundary returns an Option to apply a boundary to all patterns.  A
+// boundary given to a pattern by Bounded takes precedence over it.
+// Boundaries can't be serialized, a matcher with them reports
+// ErrorUnserializable on serializing.
+func WithBoundary(b Boundary) Option {
+	return func(c *config) {
+		c.boundary = b
+	}
+}
+
+// Bounded returns a PatternOption to apply a boundary to the pattern.
+// Values appended to the pattern by Append policy share the boundary of the
+// first one.
+func Bounded(b Boundary) PatternOption {
+	return func(c *patternConfig) {
+		c.boundary = b
+	}
+}
+
+// IsWordRune checks a rune is a part of words: letters, marks, digits and
+// connector punctuations like '_'.
+func IsWordRune(r rune) bool {
+	return r == '_' || unicode.In(r, unicode.L, unicode.M, unicode.Nd, unicode.Pc)
+}
+
+// WordBoundary accepts matches which are not adjacent to word runes.
+func WordBoundary(prev, next rune) bool {
+	return !IsWordRune(prev) && !IsWordRune(next)
+}
+
+// LineStart accepts matches which start at start of lines.
+func LineStart(prev, next rune) bool {
+	return prev == NoRune || prev == '\n' || prev == '\r'
+}
+
+// LineEnd accepts matches which end at end of lines.
+func LineEnd(prev, next rune) bool {
+	return next == NoRune || next == '\n' || next == '\r'
+}
+
+// WholeLine accepts matches which are whole lines.
+func WholeLine(prev, next rune) bool {
+	return LineStart(prev, next) && LineEnd(prev, next)
+}
+
+// AllOf returns a Boundary which accepts matches accepted by all of bs.
+func AllOf(bs ...Boundary) Boundary {
+	return func(prev, next rune) bool {
+		for _, b := range bs {
+			if !b(prev, next) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,81 @@
+package ahocorasick
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordBoundary(t *testing.T) {
+	m := New()
+	m.Add("cat", 1)
+	m.Add("concat", 2)
+	m.Add("c++", 3)
+	text := "cat concatenate concat, c++ cat_ (cat)"
+	exp := []Match{
+		{Index: 0, Pattern: "cat", Value: 1},
+		{Index: 16, Pattern: "concat", Value: 2},
+		{Index: 24, Pattern: "c++", Value: 3},
+		{Index: 34, Pattern: "cat", Value: 1},
+	}
+	for _, opts := range [][]Option{
+		{WithBoundary(WordBoundary)},
+		{WithBoundary(WordBoundary), WithDFA()},
+	} {
+		m.Compile(opts...)
+		assertMatches(t, exp, matchAll(t, m, text))
+		assertMatches(t, exp, matchReaderAll(t, m, strings.NewReader(text)))
+	}
+}
+
+func TestBoundaryLeftmost(t *testing.T) {
+	m := New()
+	m.Add("New York", 1)
+	m.Add("York", 2)
+	m.Add("New", 3)
+	m.Compile(WithMode(LeftmostLongest), WithBoundary(WordBoundary))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "New", Value: 3},
+		{Index: 14, Pattern: "York", Value: 2},
+	}, matchAll(t, m, "New Yorker in York"))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "New York", Value: 1},
+	}, matchAll(t, m, "New York"))
+	if s := replaceAllFunc(t, m, "New Yorker in York", func(v Match) string {
+		return "<" + v.Pattern + ">"
+	}); s != "<New> Yorker in <York>" {
+		t.Errorf("unexpected replacement: %q", s)
+	}
+}
+
+func TestPatternBoundary(t *testing.T) {
+	m := New()
+	m.Add("ERROR", 1, Bounded(AllOf(LineStart, WordBoundary)))
+	m.Add("done", 2, Bounded(LineEnd))
+	m.Add("x", 3)
+	m.Compile(WithFold(FoldCase))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ERROR", Value: 1},
+		{Index: 10, Pattern: "x", Value: 3},
+		{Index: 18, Pattern: "done", Value: 2},
+		{Index: 24, Pattern: "ERROR", Value: 1},
+		{Index: 30, Pattern: "x", Value: 3},
+	}, matchAll(t, m, "error: no x error done\r\nError x errors done."))
+	if _, err := m.MarshalBinary(); err != ErrorUnserializable {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBoundaryFold(t *testing.T) {
+	m := New()
+	m.Add("カ", 1)
+	m.Compile(WithFold(FoldWidth), WithBoundary(WholeLine))
+	assertMatches(t, []Match{
+		{Index: 7, Pattern: "カ", Value: 1},
+	}, matchAll(t, m, "ｶﾞ\nｶ"))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "カ", Value: 1},
+	}, matchAll(t, m, "カ\nｶx"))
+	if _, err := m.MarshalBinary(); err != ErrorUnserializable {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package ahocorasick
+
+import (
+	"github.com/koron/gelatin/trie"
+)
+
+// dfa is a dense form of the automaton.  All transitions including failures
+// are resolved in compile, so each input rune needs just one table lookup.
+type dfa[V any] struct {
+	// ascii and classes map runes to classes.  Class 0 is for runes which
+	// don't appear in any patterns, they all lead to the root.
+	ascii   [128]int32
+	classes map[rune]int32
+	nclass  int
+	// trans is the transition table: trans[state*nclass+class].
+	trans []int32
+	// outs holds flattened outputs, outs[outIdx[s]:outIdx[s+1]] are outputs
+	// of state s.
+	outs   []*nodeData[V]
+	outIdx []int32
+	depth  []int
+}
+
+func newDFA[V any](t *trie.TernaryTrie) *dfa[V] {
+	root := t.Root().(*trie.TernaryNode)
+	// number states in width order, then failure of a state always has
+	// smaller number than the state.
+	var nodes []*trie.TernaryNode
+	ids := map[*trie.TernaryNode]int32{}
+	labels := map[rune]int32{}
+	trie.EachWidth(t, func(n trie.Node) bool {
+		tn := n.(*trie.TernaryNode)
+		ids[tn] = int32(len(nodes))
+		nodes = append(nodes, tn)
+		if tn != root {
+			if _, ok := labels[tn.Label()]; !ok {
+				labels[tn.Label()] = int32(len(labels) + 1)
+			}
+		}
+		return true
+	})
+
+	// runes which appear in patterns have own classes.  Columns of them are
+	// never identical, since only a rune leads to states labeled with it, so
+	// they can't be merged.
+	d := &dfa[V]{
+		classes: map[rune]int32{},
+		nclass:  len(labels) + 1,
+		outIdx:  make([]int32, 0, len(nodes)+1),
+		depth:   make([]int, len(nodes)),
+	}
+	for r, c := range labels {
+		if r >= 0 && r < 128 {
+			d.ascii[r] = c
+		} else {
+			d.classes[r] = c
+		}
+	}
+	d.trans = make([]int32, len(nodes)*d.nclass)
+	for i, n := range nodes {
+		row := d.trans[i*d.nclass : (i+1)*d.nclass]
+		if n != root {
+			f := int(ids[getNodeFailure[V](n, root)])
+			copy(row, d.trans[f*d.nclass:(f+1)*d.nclass])
+		}
+		n.Each(func(c trie.Node) bool {
+			row[labels[c.Label()]] = ids[c.(*trie.TernaryNode)]
+			return true
+		})
+	}
+	for i, n := range nodes {
+		d.outIdx = append(d.outIdx, int32(len(d.outs)))
+		if n != root {
+			fireAll(n, root, func(nd *nodeData[V]) bool {
+				d.outs = append(d.outs, nd)
+				return true
+			})
+		}
+		d.depth[i] = getNodeData[V](n).depth
+	}
+	d.outIdx = append(d.outIdx, int32(len(d.outs)))
+	return d
+}
+
+func (d *dfa[V]) next(state int32, r rune) int32 {
+	var c int32
+	if r >= 0 && r < 128 {
+		c = d.ascii[r]
+	} else {
+		c = d.classes[r]
+	}
+	return d.trans[int(state)*d.nclass+int(c)]
+}
+
+// tableBytes returns the size of the transition table in bytes.
+func (d *dfa[V]) tableBytes() int {
+	return len(d.trans) * 4
+}
+
+func (d *dfa[V]) outputs(state int32) []*nodeData[V] {
+	return d.outs[d.outIdx[state]:d.outIdx[state+1]]
+}
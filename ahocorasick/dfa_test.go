@@ -0,0 +1,63 @@
+package ahocorasick
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDFA(t *testing.T) {
+	m := newTestMatcher()
+	texts := []string{"", "abcde", "xxabcdexxbabxx", "babcdbabc", "ababab"}
+	var exp [][]Match
+	for _, s := range texts {
+		exp = append(exp, matchAll(t, m, s))
+	}
+	m.Compile(WithDFA())
+	if m.dfa == nil {
+		t.Fatal("DFA isn't compiled")
+	}
+	for i, s := range texts {
+		assertMatches(t, exp[i], matchAll(t, m, s))
+	}
+}
+
+func TestDFAClasses(t *testing.T) {
+	m := New()
+	for i, p := range []string{"ab", "ac", "日本", "本日"} {
+		m.Add(p, i)
+	}
+	m.Compile(WithDFA())
+	if m.dfa.ascii['a'] == m.dfa.ascii['b'] {
+		t.Error("'a' and 'b' should be in different classes")
+	}
+	if m.dfa.ascii['z'] != 0 || m.dfa.classes['語'] != 0 {
+		t.Error("runes not in patterns should be in class 0")
+	}
+	if n := m.dfa.nclass; n != 6 {
+		t.Errorf("unexpected number of classes: %d", n)
+	}
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ab", Value: 0},
+		{Index: 3, Pattern: "ac", Value: 1},
+		{Index: 5, Pattern: "日本", Value: 2},
+		{Index: 8, Pattern: "本日", Value: 3},
+		{Index: 11, Pattern: "日本", Value: 2},
+	}, matchAll(t, m, "ab ac日本日本"))
+}
+
+func TestDFALeftmost(t *testing.T) {
+	m := newModeMatcher(LeftmostLongest, "abcd", "b", "ef", "abcdefgh")
+	text := "abcdefgXxbcdefgX"
+	exp := matchAll(t, m, text)
+	m.Compile(WithMode(LeftmostLongest), WithDFA())
+	assertMatches(t, exp, matchAll(t, m, text))
+	assertMatches(t, exp, matchReaderAll(t, m, strings.NewReader(text)))
+}
+
+func BenchmarkEachDFA(b *testing.B) {
+	m := newTestMatcher()
+	m.Compile(WithDFA())
+	for i := 0; i < b.N; i++ {
+		m.Each("xxabcdexxbabxx", func(Match) bool { return true })
+	}
+}
@@ -0,0 +1,85 @@
+package ahocorasick
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDuplicatePolicy(t *testing.T) {
+	for _, c := range []struct {
+		policy DuplicatePolicy
+		exp    []Match
+	}{
+		{Overwrite, []Match{{Index: 1, Pattern: "ab", Value: 2}, {Index: 3, Pattern: "c", Value: 3}}},
+		{KeepFirst, []Match{{Index: 1, Pattern: "ab", Value: 1}, {Index: 3, Pattern: "c", Value: 3}}},
+		{Append, []Match{{Index: 1, Pattern: "ab", Value: 1}, {Index: 1, Pattern: "ab", Value: 2}, {Index: 3, Pattern: "c", Value: 3}}},
+	} {
+		m := New()
+		m.SetDuplicatePolicy(c.policy)
+		for i, p := range []string{"ab", "ab", "c"} {
+			if err := m.Add(p, i+1); err != nil {
+				t.Fatalf("Add(%q) failed: %v", p, err)
+			}
+		}
+		m.Compile()
+		assertMatches(t, c.exp, matchAll(t, m, "xabc"))
+	}
+}
+
+func TestDuplicateReject(t *testing.T) {
+	m := New()
+	m.SetDuplicatePolicy(Reject)
+	if err := m.Add("ab", 1); err != nil {
+		t.Fatal("Add failed:", err)
+	}
+	err := m.Add("ab", 2)
+	var dup *ErrorDuplicatedPattern
+	if !errors.As(err, &dup) || dup.Pattern != "ab" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.Add("AB", 3)
+	if err := m.Compile(); err != nil {
+		t.Fatal("Compile failed:", err)
+	}
+	err = m.Compile(WithFold(FoldCase))
+	if !errors.As(err, &dup) || dup.Pattern != "AB" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = m.Each("ab", func(Match) bool { return true })
+	if !errors.As(err, &dup) {
+		t.Errorf("Each returns unexpected error: %v", err)
+	}
+	m.Remove("AB")
+	assertMatches(t, []Match{{Index: 0, Pattern: "ab", Value: 1}}, matchAll(t, m, "Ab"))
+}
+
+func TestDuplicateAppendModes(t *testing.T) {
+	m := New()
+	m.SetDuplicatePolicy(Append)
+	m.Add("生", "sei")
+	m.Add("生", "nama")
+	m.Add("生", "iki")
+	m.Add("生き", "ikiru")
+	exp := []Match{
+		{Index: 0, Pattern: "生", Value: "sei"},
+		{Index: 0, Pattern: "生", Value: "nama"},
+		{Index: 0, Pattern: "生", Value: "iki"},
+	}
+	m.Compile(WithMode(LeftmostFirst))
+	assertMatches(t, exp, matchAll(t, m, "生き"))
+	m.Compile(WithMode(LeftmostLongest), WithDFA())
+	assertMatches(t, []Match{{Index: 0, Pattern: "生き", Value: "ikiru"}}, matchAll(t, m, "生き"))
+	assertMatches(t, exp, matchAll(t, m, "生"))
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal("MarshalBinary failed:", err)
+	}
+	m2 := New()
+	if err := m2.UnmarshalBinary(data); err != nil {
+		t.Fatal("UnmarshalBinary failed:", err)
+	}
+	assertMatches(t, exp, matchAll(t, m2, "生"))
+	m2.Add("生", "shou")
+	assertMatches(t, append(exp, Match{Index: 0, Pattern: "生", Value: "shou"}), matchAll(t, m2, "生"))
+}
@@ -0,0 +1,51 @@
+package ahocorasick
+
+import (
+	"context"
+	"iter"
+)
+
+// Each calls proc for each match in text synchronously.  It stops scanning
+// when proc returns false.
+func (m *MatcherOf[V]) Each(text string, proc func(MatchOf[V]) bool) error {
+	s, err := m.newScanner()
+	if err != nil {
+		return err
+	}
+	scanString(s, text, proc)
+	return nil
+}
+
+// All returns an iterator over matches in text.  It returns
+// ErrorNotCompiled or an error of compiling changed patterns before
+// iterating.
+func (m *MatcherOf[V]) All(text string) (iter.Seq[MatchOf[V]], error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	return func(yield func(MatchOf[V]) bool) {
+		m.Each(text, yield)
+	}, nil
+}
+
+// MatchContext works like Match, but the goroutine which scans text
+// terminates and closes the channel when ctx is done.
+func (m *MatcherOf[V]) MatchContext(ctx context.Context, text string) (<-chan MatchOf[V], error) {
+	s, err := m.newScanner()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan MatchOf[V], 1)
+	go func() {
+		defer close(ch)
+		scanString(s, text, func(v MatchOf[V]) bool {
+			select {
+			case ch <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch, nil
+}
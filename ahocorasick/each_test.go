@@ -0,0 +1,81 @@
+package ahocorasick
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEach(t *testing.T) {
+	m := newTestMatcher()
+	var all []Match
+	m.Each("abcdeabcde", func(v Match) bool {
+		all = append(all, v)
+		return len(all) < 3
+	})
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ab", Value: 2},
+		{Index: 1, Pattern: "bc", Value: 4},
+		{Index: 3, Pattern: "d", Value: 7},
+	}, all)
+}
+
+func TestAll(t *testing.T) {
+	m := newTestMatcher()
+	var all []Match
+	seq, err := m.All("abcde")
+	if err != nil {
+		t.Fatal("All failed:", err)
+	}
+	for v := range seq {
+		all = append(all, v)
+	}
+	assertMatches(t, matchAll(t, m, "abcde"), all)
+
+	all = all[:0]
+	for v := range seq {
+		if v.Index > 0 {
+			break
+		}
+		all = append(all, v)
+	}
+	assertMatches(t, []Match{{Index: 0, Pattern: "ab", Value: 2}}, all)
+}
+
+func TestMatchContext(t *testing.T) {
+	m := newTestMatcher()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := m.MatchContext(ctx, "abcdeabcdeabcdeabcde")
+	if err != nil {
+		t.Fatal("MatchContext failed:", err)
+	}
+	<-ch
+	cancel()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("channel isn't closed after cancel")
+		}
+	}
+}
+
+func BenchmarkEach(b *testing.B) {
+	m := newTestMatcher()
+	for i := 0; i < b.N; i++ {
+		m.Each("xxabcdexxbabxx", func(Match) bool { return true })
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	m := newTestMatcher()
+	for i := 0; i < b.N; i++ {
+		ch, _ := m.Match("xxabcdexxbabxx")
+		for range ch {
+		}
+	}
+}
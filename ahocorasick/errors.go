@@ -0,0 +1,73 @@
+package ahocorasick
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrorNotCompiled raised when the matcher is used before Compile.
+var ErrorNotCompiled = errors.New("matcher is not compiled")
+
+// ErrorDuplicatedPattern raised when a pattern is added twice with Reject
+// policy.
+type ErrorDuplicatedPattern struct {
+	Pattern string
+}
+
+func (e *ErrorDuplicatedPattern) Error() string {
+	return "duplicated pattern: " + e.Pattern
+}
+
+// ErrorUnserializable raised when serializing a matcher which has
+// boundaries, given by WithBoundary or Bounded.
+var ErrorUnserializable = errors.New("patterns with boundaries can't be serialized")
+
+// ErrorInvalidFormat raised when loading data which is not a serialized
+// matcher.
+var ErrorInvalidFormat = errors.New("invalid format of serialized matcher")
+
+// ErrorUnsupportedVersion raised when loading data which is serialized by
+// unsupported version.
+var ErrorUnsupportedVersion = errors.New("unsupported version of serialized matcher")
+
+// ErrorValueType raised when a decoded value can't be a value of the
+// matcher.
+var ErrorValueType = errors.New("type of decoded value is unmatched")
+
+// ErrorChecksum raised when checksum of loading data is unmatched.
+var ErrorChecksum = errors.New("checksum of serialized matcher is unmatched")
+
+// ErrorClosed raised when writing to a ReplaceWriter which is closed.
+var ErrorClosed = errors.New("writer is closed")
+
+// ErrorEmptyPattern raised when a pattern is empty.
+var ErrorEmptyPattern = errors.New("empty pattern")
+
+// ErrorInvalidUTF8 raised when a pattern is not valid UTF-8.
+var ErrorInvalidUTF8 = errors.New("pattern is not valid UTF-8")
+
+// ErrorInvalidJSON raised when JSON of patterns is neither an array nor an
+// object.
+var ErrorInvalidJSON = errors.New("JSON of patterns should be an array or an object")
+
+// ErrorInvalidPattern raised by Compile when a pattern is invalid.  Err is
+// ErrorEmptyPattern, ErrorInvalidUTF8, ErrorWildcardSyntax or
+// ErrorNoLiteral.
+type ErrorInvalidPattern struct {
+	Pattern string
+	Err     error
+}
+
+func (e *ErrorInvalidPattern) Error() string {
+	return "invalid pattern " + strconv.Quote(e.Pattern) + ": " + e.Err.Error()
+}
+
+func (e *ErrorInvalidPattern) Unwrap() error {
+	return e.Err
+}
+
+// ErrorWildcardSyntax raised when a wildcard pattern has invalid syntax.
+var ErrorWildcardSyntax = errors.New("invalid syntax of wildcard pattern")
+
+// ErrorNoLiteral raised when a wildcard pattern has no literal runes.
+var ErrorNoLiteral = errors.New("wildcard pattern has no literal runes")
@@ -0,0 +1,143 @@
+package ahocorasick
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Fold specifies how runes in patterns and text are folded before comparing
+// them.  Folds can be combined with bitwise OR.
+type Fold int
+
+const (
+	// FoldCase folds runes with simple case folding (unicode.SimpleFold).
+	FoldCase Fold = 1 << iota
+
+	// FoldWidth folds full-width ASCII and half-width katakana into normal
+	// width forms.  A half-width katakana followed by a voiced sound mark is
+	// folded into one voiced katakana.
+	FoldWidth
+
+	// FoldKana folds katakana into hiragana.
+	FoldKana
+)
+
+// halfKana maps half-width katakana (U+FF61-U+FF9F) to full-width.
+var halfKana = []rune("。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン゛゜")
+
+// folder folds runes of text.  It holds a rune which may be composed with a
+// following voiced sound mark.
+type folder struct {
+	fold       Fold
+	held       rune
+	start, end int
+	holding    bool
+}
+
+// feed folds a rune r which is placed between start and end, and calls out
+// for each folded rune with its range in the text.
+func (f *folder) feed(r rune, start, end int, out func(rune, int, int) bool) bool {
+	if f.fold&FoldWidth == 0 {
+		return out(f.foldRune(r), start, end)
+	}
+	if f.holding {
+		f.holding = false
+		if c := composeKana(f.held, r); c != 0 {
+			return out(f.foldRune(c), f.start, end)
+		}
+		if !out(f.foldRune(f.held), f.start, f.end) {
+			return false
+		}
+	}
+	if r >= 0xFF61 && r <= 0xFF9F {
+		r = halfKana[r-0xFF61]
+		if composeKana(r, 0xFF9E) != 0 {
+			f.held, f.start, f.end, f.holding = r, start, end, true
+			return true
+		}
+	}
+	return out(f.foldRune(r), start, end)
+}
+
+// flush outputs a rune which is held.
+func (f *folder) flush(out func(rune, int, int) bool) bool {
+	if !f.holding {
+		return true
+	}
+	f.holding = false
+	return out(f.foldRune(f.held), f.start, f.end)
+}
+
+func (f *folder) foldRune(r rune) rune {
+	if f.fold&FoldWidth != 0 {
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		} else if r == 0x3000 {
+			r = ' '
+		}
+	}
+	if f.fold&FoldKana != 0 {
+		if (r >= 0x30A1 && r <= 0x30F6) || r == 0x30FD || r == 0x30FE {
+			r -= 0x60
+		}
+	}
+	if f.fold&FoldCase != 0 {
+		r = foldCase(r)
+	}
+	return r
+}
+
+// foldCase returns the smallest rune in the orbit of unicode.SimpleFold.
+func foldCase(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		return r
+	}
+	min := r
+	for c := unicode.SimpleFold(r); c != r; c = unicode.SimpleFold(c) {
+		if c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+// composeKana composes a full-width katakana and a half-width voiced sound
+// mark.  It returns 0 when they can't be composed.
+func composeKana(base, mark rune) rune {
+	switch mark {
+	case 0xFF9E:
+		if base == 'ウ' {
+			return 'ヴ'
+		}
+		if strings.ContainsRune("カキクケコサシスセソタチツテトハヒフヘホ", base) {
+			return base + 1
+		}
+	case 0xFF9F:
+		if strings.ContainsRune("ハヒフヘホ", base) {
+			return base + 2
+		}
+	}
+	return 0
+}
+
+// foldString folds all runes in s.
+func foldString(fold Fold, s string) string {
+	if fold == 0 {
+		return s
+	}
+	var b strings.Builder
+	out := func(r rune, _, _ int) bool {
+		b.WriteRune(r)
+		return true
+	}
+	f := &folder{fold: fold}
+	for i, r := range s {
+		f.feed(r, i, i, out)
+	}
+	f.flush(out)
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package ahocorasick
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldString(t *testing.T) {
+	for _, c := range []struct {
+		fold    Fold
+		in, out string
+	}{
+		{0, "AbcＡｂｃ", "AbcＡｂｃ"},
+		{FoldCase, "AbcÀàΣσςK", "ABCÀÀΣΣΣK"},
+		{FoldWidth, "Ａｂｃ１　ｶﾞｷﾞｸﾊﾟﾋﾟｳﾞｱﾞﾞ", "Abc1 ガギクパピヴア゛゛"},
+		{FoldWidth, "ｶ", "カ"},
+		{FoldKana, "カタカナとひらがなヽ", "かたかなとひらがなゝ"},
+		{FoldCase | FoldWidth | FoldKana, "ｱｲｳＡｂc", "あいうABC"},
+	} {
+		if s := foldString(c.fold, c.in); s != c.out {
+			t.Errorf("foldString(%d, %q) returns %q, expected %q",
+				c.fold, c.in, s, c.out)
+		}
+	}
+}
+
+func TestFoldMatch(t *testing.T) {
+	m := New()
+	m.Add("Gopher", 1)
+	m.Add("ガイド", 2)
+	m.Add("ぱん", 3)
+	m.Compile(WithFold(FoldCase | FoldWidth | FoldKana))
+	text := "a GOPHER, ｇｏｐｈｅｒ, ｶﾞｲﾄﾞ and パン"
+	exp := []Match{
+		{Index: 2, Pattern: "Gopher", Value: 1},
+		{Index: 10, Pattern: "Gopher", Value: 1},
+		{Index: 30, Pattern: "ガイド", Value: 2},
+		{Index: 50, Pattern: "ぱん", Value: 3},
+	}
+	assertMatches(t, exp, matchAll(t, m, text))
+	assertMatches(t, exp, matchReaderAll(t, m, strings.NewReader(text)))
+	if s, x := replaceAllFunc(t, m, text, func(v Match) string {
+		return "[" + v.Pattern + "]"
+	}), "a [Gopher], [Gopher], [ガイド] and [ぱん]"; s != x {
+		t.Errorf("ReplaceAllFunc returns %q, expected %q", s, x)
+	}
+
+	m.Compile(WithFold(FoldCase|FoldWidth|FoldKana), WithDFA())
+	assertMatches(t, exp, matchAll(t, m, text))
+}
+
+func TestFoldHeldRune(t *testing.T) {
+	m := New()
+	m.Add("カ", 1)
+	m.Add("ガ", 2)
+	m.Compile(WithFold(FoldWidth))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "カ", Value: 1},
+		{Index: 3, Pattern: "ガ", Value: 2},
+		{Index: 10, Pattern: "カ", Value: 1},
+	}, matchAll(t, m, "ｶｶﾞxｶ"))
+}
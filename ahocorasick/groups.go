@@ -0,0 +1,109 @@
+package ahocorasick
+
+import "math/bits"
+
+// GroupSet is a set of group IDs from 0 to 63.
+type GroupSet uint64
+
+// AllGroups is a GroupSet which contains all groups.
+const AllGroups = ^GroupSet(0)
+
+// Groups returns a GroupSet which contains ids.  IDs out of range are
+// ignored.
+func Groups(ids ...int) GroupSet {
+	var g GroupSet
+	for _, id := range ids {
+		if id >= 0 && id < 64 {
+			g |= 1 << uint(id)
+		}
+	}
+	return g
+}
+
+// Has checks the set contains group id.
+func (g GroupSet) Has(id int) bool {
+	return id >= 0 && id < 64 && g&(1<<uint(id)) != 0
+}
+
+// IDs returns group IDs in the set in ascending order.
+func (g GroupSet) IDs() []int {
+	var ids []int
+	for g != 0 {
+		id := bits.TrailingZeros64(uint64(g))
+		ids = append(ids, id)
+		g &^= 1 << uint(id)
+	}
+	return ids
+}
+
+// InGroups returns a PatternOption to tag the pattern with group ids.  A
+// pattern without groups is reported regardless of enabled groups.
+func InGroups(ids ...int) PatternOption {
+	return func(c *patternConfig) {
+		c.groups |= Groups(ids...)
+	}
+}
+
+// EachInGroups works like Each, but it reports only matches of patterns in
+// enabled groups and ones without groups.  Patterns in disabled groups are
+// skipped before choosing non-overlapping matches.
+func (m *MatcherOf[V]) EachInGroups(text string, enabled GroupSet, proc func(MatchOf[V]) bool) error {
+	s, err := m.newScanner()
+	if err != nil {
+		return err
+	}
+	s.groups = enabled
+	scanString(s, text, proc)
+	return nil
+}
+
+// CountGroups returns the number of matches in text for each group, of
+// patterns in enabled groups.  A match of a pattern in two or more groups
+// is counted for each of its enabled groups.  Matches of patterns without
+// groups are not counted.
+func (m *MatcherOf[V]) CountGroups(text string, enabled GroupSet) (map[int]int, error) {
+	counts := map[int]int{}
+	err := m.EachInGroups(text, enabled, func(v MatchOf[V]) bool {
+		for _, id := range (v.Groups & enabled).IDs() {
+			counts[id]++
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// enabled checks d should be reported with the scanner.
+func (s *scanner[V]) enabled(d *nodeData[V]) bool {
+	return d.groups == 0 || d.groups&s.groups != 0
+}
+
+// filter returns the first enabled one in d and its duplicated patterns, and
+// other enabled ones.  It returns nil when all of them are disabled.
+func (s *scanner[V]) filter(d *nodeData[V]) (*nodeData[V], []*nodeData[V]) {
+	if len(d.dups) == 0 {
+		if s.enabled(d) {
+			return d, nil
+		}
+		return nil, nil
+	}
+	var first *nodeData[V]
+	var dups []*nodeData[V]
+	for i := -1; i < len(d.dups); i++ {
+		x := d
+		if i >= 0 {
+			x = d.dups[i]
+		}
+		if !s.enabled(x) {
+			continue
+		}
+		if first == nil {
+			first = x
+		} else {
+			dups = append(dups, x)
+		}
+	}
+	return first, dups
+}
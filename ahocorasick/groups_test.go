@@ -0,0 +1,106 @@
+package ahocorasick
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newGroupMatcher(opts ...Option) *Matcher {
+	m := New()
+	m.Add("apple", 1, InGroups(0))
+	m.Add("pen", 2, InGroups(1))
+	m.Add("pineapple", 3, InGroups(1, 2))
+	m.Add("an", 4)
+	m.Compile(opts...)
+	return m
+}
+
+func eachInGroups(t *testing.T, m *Matcher, text string, enabled GroupSet) []Match {
+	t.Helper()
+	var all []Match
+	if err := m.EachInGroups(text, enabled, func(v Match) bool {
+		all = append(all, v)
+		return true
+	}); err != nil {
+		t.Fatal("EachInGroups failed:", err)
+	}
+	return all
+}
+
+func TestGroupSet(t *testing.T) {
+	g := Groups(0, 3, 63, 64, -1)
+	if !g.Has(0) || !g.Has(3) || !g.Has(63) || g.Has(1) || g.Has(64) {
+		t.Errorf("unexpected GroupSet: %x", uint64(g))
+	}
+	if ids := g.IDs(); !reflect.DeepEqual(ids, []int{0, 3, 63}) {
+		t.Errorf("unexpected IDs: %v", ids)
+	}
+}
+
+func TestEachInGroups(t *testing.T) {
+	m := newGroupMatcher()
+	text := "an apple pen pineapple"
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "an", Value: 4},
+		{Index: 9, Pattern: "pen", Value: 2},
+		{Index: 13, Pattern: "pineapple", Value: 3},
+	}, eachInGroups(t, m, text, Groups(1)))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "an", Value: 4},
+	}, eachInGroups(t, m, text, 0))
+	assertMatches(t, matchAll(t, m, text), eachInGroups(t, m, text, AllGroups))
+
+	// disabled patterns don't hide others in leftmost modes.
+	m = newGroupMatcher(WithMode(LeftmostLongest))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "an", Value: 4},
+		{Index: 3, Pattern: "apple", Value: 1},
+		{Index: 13, Pattern: "apple", Value: 1},
+	}, eachInGroups(t, m, "an apple pineapple", Groups(0)))
+}
+
+func TestEachInGroupsDuplicated(t *testing.T) {
+	m := New()
+	m.SetDuplicatePolicy(Append)
+	m.Add("x", 1, InGroups(0))
+	m.Add("x", 2, InGroups(1))
+	m.Add("x", 3, InGroups(1))
+	m.Compile()
+	act := eachInGroups(t, m, "x", Groups(1))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "x", Value: 2},
+		{Index: 0, Pattern: "x", Value: 3},
+	}, act)
+	if act[0].Groups != Groups(1) {
+		t.Errorf("unexpected groups: %x", uint64(act[0].Groups))
+	}
+}
+
+func TestCountGroups(t *testing.T) {
+	m := newGroupMatcher()
+	counts, err := m.CountGroups("an apple pen pineapple", Groups(0, 1))
+	if err != nil {
+		t.Fatal("CountGroups failed:", err)
+	}
+	if exp := map[int]int{0: 2, 1: 2}; !reflect.DeepEqual(counts, exp) {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+	counts, _ = m.CountGroups("an apple pen pineapple", Groups(1))
+	if exp := map[int]int{1: 2}; !reflect.DeepEqual(counts, exp) {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+}
+
+func TestGroupsSerialize(t *testing.T) {
+	m := newGroupMatcher(WithMode(LeftmostLongest))
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal("MarshalBinary failed:", err)
+	}
+	m2 := New()
+	if err := m2.UnmarshalBinary(data); err != nil {
+		t.Fatal("UnmarshalBinary failed:", err)
+	}
+	text := "an apple pen pineapple"
+	assertMatches(t, eachInGroups(t, m, text, Groups(0)), eachInGroups(t, m2, text, Groups(0)))
+}
@@ -0,0 +1,120 @@
+package ahocorasick
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Holder is a HolderOf which holds a Matcher.
+type Holder = HolderOf[any]
+
+// HolderOf holds a compiled matcher which is swapped atomically.  Matching
+// which started with an old matcher finishes with it, and later ones use new
+// one.  It is safe for concurrent use by multiple goroutines.
+type HolderOf[V any] struct {
+	p atomic.Pointer[MatcherOf[V]]
+}
+
+// NewHolder creates a HolderOf which holds m.  m may be nil, then the holder
+// is empty until Store or Rebuild.
+func NewHolder[V any](m *MatcherOf[V]) *HolderOf[V] {
+	h := &HolderOf[V]{}
+	if m != nil {
+		h.p.Store(m)
+	}
+	return h
+}
+
+// Load returns the current matcher, or nil when the holder is empty.
+func (h *HolderOf[V]) Load() *MatcherOf[V] {
+	return h.p.Load()
+}
+
+// Store replaces the current matcher with m, and returns the old one.  m
+// should be compiled and should not be changed after this.
+func (h *HolderOf[V]) Store(m *MatcherOf[V]) *MatcherOf[V] {
+	return h.p.Swap(m)
+}
+
+// Rebuild builds a new matcher with build, and replaces the current one with
+// it.  The current one is kept when build fails.  It returns
+// ErrorNotCompiled when the new matcher is not compiled.
+func (h *HolderOf[V]) Rebuild(build func() (*MatcherOf[V], error)) error {
+	m, err := build()
+	if err != nil {
+		return err
+	}
+	// compile pending changes here, not in matching.
+	if err := m.prepare(); err != nil {
+		return err
+	}
+	h.p.Store(m)
+	return nil
+}
+
+// Each calls proc for each match in text with the current matcher.  It
+// returns ErrorNotCompiled when the holder is empty.
+func (h *HolderOf[V]) Each(text string, proc func(MatchOf[V]) bool) error {
+	m := h.p.Load()
+	if m == nil {
+		return ErrorNotCompiled
+	}
+	return m.Each(text, proc)
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch polls a pattern file name every interval, and rebuilds the matcher
+// with build when modification time or size of the file is changed.  The
+// file is read at first regardless of them.  Errors on reading the file or
+// building are passed to report when it isn't nil, and the current matcher
+// is kept.  Watch blocks until ctx is done, and returns ctx.Err().
+func (h *HolderOf[V]) Watch(ctx context.Context, name string, interval time.Duration, build func(io.Reader) (*MatcherOf[V], error), report func(error)) error {
+	var last fileStamp
+	loaded := false
+	check := func() {
+		fi, err := os.Stat(name)
+		if err != nil {
+			loaded = false
+			if report != nil {
+				report(err)
+			}
+			return
+		}
+		stamp := fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+		if loaded && stamp.modTime.Equal(last.modTime) && stamp.size == last.size {
+			return
+		}
+		err = h.Rebuild(func() (*MatcherOf[V], error) {
+			f, err := os.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return build(f)
+		})
+		// failed builds are retried only after the file is changed.
+		last, loaded = stamp, true
+		if err != nil && report != nil {
+			report(err)
+		}
+	}
+	check()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			check()
+		}
+	}
+}
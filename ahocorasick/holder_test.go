@@ -0,0 +1,158 @@
+package ahocorasick
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func buildLines(r io.Reader) (*MatcherOf[any], error) {
+	m := New()
+	if err := LoadLines(m, r, SkipBlank()); err != nil {
+		return nil, err
+	}
+	return m, m.Compile()
+}
+
+func TestHolder(t *testing.T) {
+	h := NewHolder[any](nil)
+	if err := h.Each("abc", func(Match) bool { return true }); err != ErrorNotCompiled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	m := New()
+	m.Add("b", 1)
+	if err := h.Rebuild(func() (*Matcher, error) { return m, nil }); err != ErrorNotCompiled {
+		t.Errorf("uncompiled matcher is stored: %v", err)
+	}
+	m.Compile()
+	if err := h.Rebuild(func() (*Matcher, error) { return m, nil }); err != nil {
+		t.Fatal("Rebuild failed:", err)
+	}
+	errBuild := errors.New("build failed")
+	if err := h.Rebuild(func() (*Matcher, error) { return nil, errBuild }); err != errBuild {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if h.Load() != m {
+		t.Error("matcher is replaced by failed Rebuild")
+	}
+	var all []Match
+	h.Each("abc", func(v Match) bool {
+		all = append(all, v)
+		return true
+	})
+	assertMatches(t, []Match{{Index: 1, Pattern: "b", Value: 1}}, all)
+
+	m2 := New()
+	m2.Compile()
+	if old := h.Store(m2); old != m {
+		t.Error("Store returned unexpected matcher")
+	}
+}
+
+func TestHolderConcurrent(t *testing.T) {
+	h := NewHolder[any](nil)
+	build := func(p string) func() (*Matcher, error) {
+		return func() (*Matcher, error) {
+			m := New()
+			m.Add(p, nil)
+			return m, m.Compile()
+		}
+	}
+	h.Rebuild(build("a"))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				n := 0
+				h.Each("ab", func(Match) bool {
+					n++
+					return true
+				})
+				if n != 1 {
+					t.Errorf("unexpected number of matches: %d", n)
+					return
+				}
+			}
+		}()
+	}
+	for _, p := range []string{"b", "ab", "a"} {
+		h.Rebuild(build(p))
+	}
+	wg.Wait()
+}
+
+// waitFor polls cond until it returns true or timeout.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHolderWatch(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "patterns.txt")
+	if err := os.WriteFile(name, []byte("apple\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHolder[any](nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var errs []error
+	done := make(chan error)
+	go func() {
+		done <- h.Watch(ctx, name, 10*time.Millisecond, buildLines, func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		})
+	}()
+	found := func(text string) func() bool {
+		return func() bool {
+			n := 0
+			h.Each(text, func(Match) bool {
+				n++
+				return true
+			})
+			return n > 0
+		}
+	}
+	waitFor(t, found("an apple"))
+
+	// the current matcher is kept while the file is invalid.
+	old := h.Load()
+	if err := os.WriteFile(name, []byte("\xff\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) > 0
+	})
+	if h.Load() != old {
+		t.Error("matcher is replaced by invalid file")
+	}
+
+	if err := os.WriteFile(name, []byte("banana\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	// make sure that modification time is changed.
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(name, later, later)
+	waitFor(t, found("a banana"))
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package ahocorasick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/koron/gelatin/trie"
+)
+
+// State is a read-only view of a state of the compiled automaton.  States
+// are numbered in width order from the root, which is 0.
+type State struct {
+	ID int
+	// Depth is length of Path in runes.
+	Depth int
+	// Path is the sequence of (folded) runes from the root to the state.  Each
+	// rune is a byte in byte mode.
+	Path string
+	// Edges are goto transitions sorted by their runes.
+	Edges []Edge
+	// Failure is the state which the automaton falls back to when no edges
+	// accept a rune.  The failure of the root is the root.
+	Failure int
+	// Patterns are patterns whose keys end at the state, including wildcard
+	// patterns whose anchors end at it.
+	Patterns []string
+	// Outputs are patterns which are reported when the automaton enters the
+	// state, the state's own ones come first and then ones of its failures.
+	Outputs []string
+}
+
+// Edge is a goto transition of the automaton.
+type Edge struct {
+	Rune rune
+	To   int
+}
+
+// Step is a transition of the automaton for a rune in Explain.
+type Step struct {
+	// Index and End are byte offsets of the rune in the text.
+	Index int
+	End   int
+	// Rune is the folded rune which is fed to the automaton.
+	Rune rune
+	From int
+	To   int
+	// Hops are failure states which the automaton falls back to before it
+	// takes an edge or stays at the root.
+	Hops []int
+	// Outputs are patterns which are reported at the state To before modes
+	// and boundaries are applied.
+	Outputs []string
+}
+
+// states numbers nodes of the compiled trie in width order.
+func (m *MatcherOf[V]) states() ([]*trie.TernaryNode, map[*trie.TernaryNode]int, error) {
+	if err := m.prepare(); err != nil {
+		return nil, nil, err
+	}
+	var nodes []*trie.TernaryNode
+	ids := map[*trie.TernaryNode]int{}
+	trie.EachWidth(m.trie, func(n trie.Node) bool {
+		tn := n.(*trie.TernaryNode)
+		ids[tn] = len(nodes)
+		nodes = append(nodes, tn)
+		return true
+	})
+	return nodes, ids, nil
+}
+
+// States returns all states of the compiled automaton in order of their IDs.
+func (m *MatcherOf[V]) States() ([]State, error) {
+	nodes, ids, err := m.states()
+	if err != nil {
+		return nil, err
+	}
+	root := nodes[0]
+	states := make([]State, len(nodes))
+	paths := make([][]rune, len(nodes))
+	for i, n := range nodes {
+		st := &states[i]
+		d := getNodeData[V](n)
+		st.ID = i
+		st.Depth = d.depth
+		st.Path = string(paths[i])
+		st.Failure = ids[getNodeFailure[V](n, root)]
+		n.Each(func(c trie.Node) bool {
+			to := ids[c.(*trie.TernaryNode)]
+			st.Edges = append(st.Edges, Edge{Rune: c.Label(), To: to})
+			paths[to] = append(append([]rune(nil), paths[i]...), c.Label())
+			return true
+		})
+		st.Patterns = d.patterns(nil)
+		if n != root {
+			fireAll(n, root, func(d *nodeData[V]) bool {
+				st.Outputs = d.patterns(st.Outputs)
+				return true
+			})
+		}
+	}
+	return states, nil
+}
+
+// patterns appends patterns of d, its duplicates and wildcard patterns to
+// dst.
+func (d *nodeData[V]) patterns(dst []string) []string {
+	if d.pattern != nil {
+		dst = append(dst, *d.pattern)
+		for _, x := range d.dups {
+			dst = append(dst, *x.pattern)
+		}
+	}
+	for _, x := range d.globs {
+		dst = append(dst, *x.pattern)
+	}
+	return dst
+}
+
+// WriteDOT writes the compiled automaton to w in DOT language of Graphviz.
+// States with patterns are drawn with double circles, and failures except
+// ones to the root are drawn with dashed edges.
+func (m *MatcherOf[V]) WriteDOT(w io.Writer) error {
+	states, err := m.States()
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph automaton {\n\trankdir=LR;\n\tnode [shape=circle];\n")
+	for _, st := range states {
+		label := strconv.Itoa(st.ID)
+		for _, p := range st.Patterns {
+			label += "\n" + p
+		}
+		shape := ""
+		if len(st.Patterns) > 0 {
+			shape = " shape=doublecircle"
+		}
+		fmt.Fprintf(bw, "\t%d [label=%s%s];\n", st.ID, dotQuote(label), shape)
+	}
+	for _, st := range states {
+		for _, e := range st.Edges {
+			fmt.Fprintf(bw, "\t%d -> %d [label=%s];\n", st.ID, e.To, dotQuote(string(e.Rune)))
+		}
+		if st.Failure != 0 {
+			fmt.Fprintf(bw, "\t%d -> %d [style=dashed color=gray];\n", st.ID, st.Failure)
+		}
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// dotQuote quotes s as a string of DOT language.
+func dotQuote(s string) string {
+	b := []byte{'"'}
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b = append(b, '\\', byte(r))
+		case '\n':
+			b = append(b, '\\', 'n')
+		default:
+			b = append(b, string(r)...)
+		}
+	}
+	return string(append(b, '"'))
+}
+
+// Explain runs the automaton over text and returns its steps for each
+// (folded) rune.  It shows how the automaton finds matches regardless of
+// modes and boundaries.
+func (m *MatcherOf[V]) Explain(text string) ([]Step, error) {
+	nodes, ids, err := m.states()
+	if err != nil {
+		return nil, err
+	}
+	root := nodes[0]
+	curr := root
+	var steps []Step
+	for _, t := range m.foldText(text) {
+		st := Step{Index: t.start, End: t.end, Rune: t.r, From: ids[curr]}
+		for {
+			if next, _ := curr.Get(t.r).(*trie.TernaryNode); next != nil {
+				curr = next
+				break
+			} else if curr == root {
+				break
+			}
+			curr = getNodeFailure[V](curr, root)
+			st.Hops = append(st.Hops, ids[curr])
+		}
+		st.To = ids[curr]
+		if curr != root {
+			fireAll(curr, root, func(d *nodeData[V]) bool {
+				st.Outputs = d.patterns(st.Outputs)
+				return true
+			})
+		}
+		steps = append(steps, st)
+	}
+	return steps, nil
+}
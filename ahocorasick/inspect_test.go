@@ -0,0 +1,111 @@
+package ahocorasick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStates(t *testing.T) {
+	states, err := newTestMatcher().States()
+	if err != nil {
+		t.Fatal("States failed:", err)
+	}
+	type view struct {
+		path    string
+		failure string
+		outputs []string
+	}
+	var act []view
+	for i, st := range states {
+		if st.ID != i || st.Depth != len(st.Path) {
+			t.Errorf("unexpected state: %+v", st)
+		}
+		for _, e := range st.Edges {
+			if p := states[e.To].Path; p != st.Path+string(e.Rune) {
+				t.Errorf("unexpected edge from %q: %q", st.Path, p)
+			}
+		}
+		act = append(act, view{st.Path, states[st.Failure].Path, st.Outputs})
+	}
+	exp := []view{
+		{"", "", nil},
+		{"a", "", nil},
+		{"b", "", nil},
+		{"d", "", []string{"d"}},
+		{"ab", "b", []string{"ab"}},
+		{"ba", "a", nil},
+		{"bc", "", []string{"bc"}},
+		{"abc", "bc", []string{"bc"}},
+		{"bab", "ab", []string{"bab", "ab"}},
+		{"abcd", "d", []string{"d"}},
+		{"abcde", "", []string{"abcde"}},
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected states:\n  expected: %v\n  actually: %v", exp, act)
+	}
+	if p := states[4].Patterns; !reflect.DeepEqual(p, []string{"ab"}) {
+		t.Errorf("unexpected patterns: %v", p)
+	}
+
+	if _, err := New().States(); err != ErrorNotCompiled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	m := New()
+	m.Add(`a"b`, nil)
+	m.Add("b", nil)
+	m.Compile()
+	var b strings.Builder
+	if err := m.WriteDOT(&b); err != nil {
+		t.Fatal("WriteDOT failed:", err)
+	}
+	exp := `digraph automaton {
+	rankdir=LR;
+	node [shape=circle];
+	0 [label="0"];
+	1 [label="1"];
+	2 [label="2\nb" shape=doublecircle];
+	3 [label="3"];
+	4 [label="4\na\"b" shape=doublecircle];
+	0 -> 1 [label="a"];
+	0 -> 2 [label="b"];
+	1 -> 3 [label="\""];
+	3 -> 4 [label="b"];
+	4 -> 2 [style=dashed color=gray];
+}
+`
+	if s := b.String(); s != exp {
+		t.Errorf("unexpected DOT:\n%s", s)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	steps, err := newTestMatcher().Explain("xbabc")
+	if err != nil {
+		t.Fatal("Explain failed:", err)
+	}
+	exp := []Step{
+		{Index: 0, End: 1, Rune: 'x', From: 0, To: 0},
+		{Index: 1, End: 2, Rune: 'b', From: 0, To: 2},
+		{Index: 2, End: 3, Rune: 'a', From: 2, To: 5},
+		{Index: 3, End: 4, Rune: 'b', From: 5, To: 8, Outputs: []string{"bab", "ab"}},
+		{Index: 4, End: 5, Rune: 'c', From: 8, To: 7, Hops: []int{4}, Outputs: []string{"bc"}},
+	}
+	if !reflect.DeepEqual(steps, exp) {
+		t.Errorf("unexpected steps:\n  expected: %+v\n  actually: %+v", exp, steps)
+	}
+
+	m := New()
+	m.Add("ガイド", nil)
+	m.Compile(WithFold(FoldWidth))
+	steps, err = m.Explain("ｶﾞｲﾄﾞ")
+	if err != nil {
+		t.Fatal("Explain failed:", err)
+	}
+	if len(steps) != 3 || steps[0].Rune != 'ガ' || steps[0].End != 6 || steps[2].Outputs[0] != "ガイド" {
+		t.Errorf("unexpected steps: %+v", steps)
+	}
+}
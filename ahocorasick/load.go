@@ -0,0 +1,243 @@
+package ahocorasick
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LoadOption configures loaders of patterns.
+type LoadOption func(*loadConfig)
+
+type loadConfig struct {
+	comment   string
+	trim      bool
+	skipBlank bool
+}
+
+func newLoadConfig(opts []LoadOption) loadConfig {
+	var c loadConfig
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
+
+// CommentPrefix returns a LoadOption to skip lines which start with prefix.
+// It is ignored by LoadJSON.
+func CommentPrefix(prefix string) LoadOption {
+	return func(c *loadConfig) {
+		c.comment = prefix
+	}
+}
+
+// TrimSpace returns a LoadOption to trim white spaces around patterns and
+// values.
+func TrimSpace() LoadOption {
+	return func(c *loadConfig) {
+		c.trim = true
+	}
+}
+
+// SkipBlank returns a LoadOption to skip empty patterns instead of reporting
+// ErrorEmptyPattern.
+func SkipBlank() LoadOption {
+	return func(c *loadConfig) {
+		c.skipBlank = true
+	}
+}
+
+// ErrorLoad raised when a loader finds an invalid pattern or data.  Line is
+// 1-based line number where the error is found.
+type ErrorLoad struct {
+	Line int
+	Err  error
+}
+
+func (e *ErrorLoad) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *ErrorLoad) Unwrap() error {
+	return e.Err
+}
+
+// add adds a pattern found at line to m.  It returns nil without adding a
+// pattern when the pattern is blank and it should be skipped.
+func add[V any](m *MatcherOf[V], c *loadConfig, line int, pattern string, v V) error {
+	if c.trim {
+		pattern = strings.TrimSpace(pattern)
+	}
+	var err error
+	switch {
+	case pattern == "" && c.skipBlank:
+		return nil
+	case pattern == "":
+		err = ErrorEmptyPattern
+	case !utf8.ValidString(pattern):
+		err = ErrorInvalidUTF8
+	default:
+		err = m.Add(pattern, v)
+	}
+	if err != nil {
+		return &ErrorLoad{Line: line, Err: err}
+	}
+	return nil
+}
+
+// isComment checks s is a comment line.
+func (c *loadConfig) isComment(s string) bool {
+	if c.trim {
+		s = strings.TrimSpace(s)
+	}
+	return c.comment != "" && strings.HasPrefix(s, c.comment)
+}
+
+// eachLine calls proc for each line in r without "\n" and "\r\n".
+func eachLine(r io.Reader, proc func(n int, line string) error) error {
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		if err := proc(n, strings.TrimSuffix(sc.Text(), "\r")); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// LoadLines adds patterns in r to m, one pattern per line.  Values of the
+// patterns are zero values.
+func LoadLines[V any](m *MatcherOf[V], r io.Reader, opts ...LoadOption) error {
+	c := newLoadConfig(opts)
+	var zero V
+	return eachLine(r, func(n int, line string) error {
+		if c.isComment(line) {
+			return nil
+		}
+		return add(m, &c, n, line, zero)
+	})
+}
+
+// LoadTSV adds patterns in r to m.  Each line has a pattern and a value
+// separated by a tab, the value may contain tabs.  A line without tabs has
+// an empty value.
+func LoadTSV(m *MatcherOf[string], r io.Reader, opts ...LoadOption) error {
+	c := newLoadConfig(opts)
+	return eachLine(r, func(n int, line string) error {
+		if c.isComment(line) {
+			return nil
+		}
+		pattern, value, _ := strings.Cut(line, "\t")
+		if c.trim {
+			value = strings.TrimSpace(value)
+		}
+		return add(m, &c, n, pattern, value)
+	})
+}
+
+// LoadCSV adds patterns in r to m.  Each record has a pattern in the first
+// field and a value in the second field which may be omitted.  Other fields
+// are ignored.
+func LoadCSV(m *MatcherOf[string], r io.Reader, opts ...LoadOption) error {
+	c := newLoadConfig(opts)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = c.trim
+	if n := utf8.RuneCountInString(c.comment); n == 1 {
+		cr.Comment, _ = utf8.DecodeRuneInString(c.comment)
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				return &ErrorLoad{Line: perr.Line, Err: perr.Err}
+			}
+			return err
+		}
+		line, _ := cr.FieldPos(0)
+		if c.isComment(rec[0]) {
+			continue
+		}
+		var value string
+		if len(rec) > 1 {
+			value = rec[1]
+			if c.trim {
+				value = strings.TrimSpace(value)
+			}
+		}
+		if err := add(m, &c, line, rec[0], value); err != nil {
+			return err
+		}
+	}
+}
+
+// LoadJSON adds patterns in r to m.  The JSON is an array of patterns, or an
+// object which maps patterns to their values.  Values of patterns in an
+// array are zero values.  Patterns in an object are added in order of the
+// document.
+func LoadJSON[V any](m *MatcherOf[V], r io.Reader, opts ...LoadOption) error {
+	c := newLoadConfig(opts)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	lineOf := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+	fail := func(err error) error {
+		var serr *json.SyntaxError
+		if errors.As(err, &serr) {
+			return &ErrorLoad{Line: lineOf(serr.Offset), Err: err}
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return &ErrorLoad{Line: lineOf(d.InputOffset()), Err: err}
+	}
+	tok, err := d.Token()
+	if err != nil {
+		return fail(err)
+	}
+	switch tok {
+	case json.Delim('['):
+		var zero V
+		for d.More() {
+			var pattern string
+			if err := d.Decode(&pattern); err != nil {
+				return fail(err)
+			}
+			if err := add(m, &c, lineOf(d.InputOffset()), pattern, zero); err != nil {
+				return err
+			}
+		}
+	case json.Delim('{'):
+		for d.More() {
+			tok, err := d.Token()
+			if err != nil {
+				return fail(err)
+			}
+			var v V
+			if err := d.Decode(&v); err != nil {
+				return fail(err)
+			}
+			if err := add(m, &c, lineOf(d.InputOffset()), tok.(string), v); err != nil {
+				return err
+			}
+		}
+	default:
+		return &ErrorLoad{Line: lineOf(d.InputOffset()), Err: ErrorInvalidJSON}
+	}
+	if _, err := d.Token(); err != nil {
+		return fail(err)
+	}
+	return nil
+}
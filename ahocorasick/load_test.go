@@ -0,0 +1,126 @@
+package ahocorasick
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func assertLoadError(t *testing.T, err error, line int, target error) {
+	t.Helper()
+	var lerr *ErrorLoad
+	if !errors.As(err, &lerr) {
+		t.Fatalf("error should be ErrorLoad: %v", err)
+	}
+	if lerr.Line != line {
+		t.Errorf("error at line %d, expected %d: %v", lerr.Line, line, err)
+	}
+	if target != nil && !errors.Is(err, target) {
+		t.Errorf("error should be %v: %v", target, err)
+	}
+}
+
+func TestLoadLines(t *testing.T) {
+	m := NewOf[int]()
+	err := LoadLines(m, strings.NewReader("# animals\ncat\r\n  dog \n\nネコ\n"),
+		CommentPrefix("#"), TrimSpace(), SkipBlank())
+	if err != nil {
+		t.Fatal("LoadLines failed:", err)
+	}
+	m.Compile()
+	var act []string
+	m.Each("a cat, dog and ネコ", func(v MatchOf[int]) bool {
+		act = append(act, v.Pattern)
+		return true
+	})
+	if s := strings.Join(act, ","); s != "cat,dog,ネコ" {
+		t.Errorf("unexpected matches: %s", s)
+	}
+
+	err = LoadLines(New(), strings.NewReader("cat\n\ndog\n"))
+	assertLoadError(t, err, 2, ErrorEmptyPattern)
+	err = LoadLines(New(), strings.NewReader("cat\ndo\xffg\n"))
+	assertLoadError(t, err, 2, ErrorInvalidUTF8)
+
+	m2 := New()
+	m2.SetDuplicatePolicy(Reject)
+	err = LoadLines(m2, strings.NewReader("cat\ndog\ncat\n"))
+	var derr *ErrorDuplicatedPattern
+	assertLoadError(t, err, 3, nil)
+	if !errors.As(err, &derr) {
+		t.Errorf("error should be ErrorDuplicatedPattern: %v", err)
+	}
+}
+
+func TestLoadTSV(t *testing.T) {
+	m := NewOf[string]()
+	err := LoadTSV(m, strings.NewReader("// comment\ncat\tdog\ttail\nbird\n sky \t blue \n"),
+		CommentPrefix("//"), TrimSpace())
+	if err != nil {
+		t.Fatal("LoadTSV failed:", err)
+	}
+	m.Compile()
+	if s := replaceAll(t, m, "cat bird sky"); s != "dog\ttail  blue" {
+		t.Errorf("unexpected replacement: %q", s)
+	}
+	err = LoadTSV(NewOf[string](), strings.NewReader("cat\tdog\n\tnone\n"))
+	assertLoadError(t, err, 2, ErrorEmptyPattern)
+}
+
+func TestLoadCSV(t *testing.T) {
+	m := NewOf[string]()
+	err := LoadCSV(m, strings.NewReader("# comment\ncat,dog\n\"a,b\",\"c\nd\"\nbird\n"), CommentPrefix("#"))
+	if err != nil {
+		t.Fatal("LoadCSV failed:", err)
+	}
+	m.Compile()
+	if s := replaceAll(t, m, "cat a,b bird"); s != "dog c\nd " {
+		t.Errorf("unexpected replacement: %q", s)
+	}
+	err = LoadCSV(NewOf[string](), strings.NewReader("cat,dog\n\"a\"b\n"))
+	assertLoadError(t, err, 2, nil)
+	err = LoadCSV(NewOf[string](), strings.NewReader("cat,dog\n\"a\nb\",x\n\"\",empty\n"))
+	assertLoadError(t, err, 4, ErrorEmptyPattern)
+}
+
+func TestLoadJSON(t *testing.T) {
+	m := NewOf[int]()
+	if err := LoadJSON(m, strings.NewReader(`{"cat": 1, "dog": 2, "category": 3}`)); err != nil {
+		t.Fatal("LoadJSON failed:", err)
+	}
+	m.Compile(WithMode(LeftmostFirst))
+	var act []int
+	m.Each("category dog", func(v MatchOf[int]) bool {
+		act = append(act, v.Value)
+		return true
+	})
+	if len(act) != 2 || act[0] != 1 || act[1] != 2 {
+		t.Errorf("unexpected values: %v", act)
+	}
+
+	m2 := New()
+	if err := LoadJSON(m2, strings.NewReader(`["cat", " dog "]`), TrimSpace()); err != nil {
+		t.Fatal("LoadJSON failed:", err)
+	}
+	m2.Compile()
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "cat", Value: nil},
+		{Index: 4, Pattern: "dog", Value: nil},
+	}, matchAll(t, m2, "cat dog"))
+
+	for _, c := range []struct {
+		in     string
+		line   int
+		target error
+	}{
+		{"[\n\"a\",\n\"\"\n]", 3, ErrorEmptyPattern},
+		{"{\n\"a\": 1,\n\"b\": \"x\"\n}", 3, nil},
+		{"[\n\"a\",\n1\n]", 3, nil},
+		{"[\n\"a\",\n", 3, nil},
+		{"\n\n\"a\"", 3, ErrorInvalidJSON},
+		{"[\"a\"\n,,]", 2, nil},
+	} {
+		err := LoadJSON(NewOf[int](), strings.NewReader(c.in))
+		assertLoadError(t, err, c.line, c.target)
+	}
+}
@@ -1,20 +1,12 @@
 package ahocorasick
 
-import (
-	"container/list"
-)
-
-func MatchAll(m *Matcher, text string) []Match {
-	list := list.New()
-	ch := m.Match(text)
-	for n := range ch {
-		list.PushBack(n)
-	}
-	all := make([]Match, list.Len())
-	idx := 0
-	for e := list.Front(); e != nil; e = e.Next() {
-		all[idx] = e.Value.(Match)
-		idx++
-	}
-	return all
+// MatchAll returns all matches in text as a slice.  It returns
+// ErrorNotCompiled or an error of compiling changed patterns like Each.
+func MatchAll[V any](m *MatcherOf[V], text string) ([]MatchOf[V], error) {
+	var all []MatchOf[V]
+	err := m.Each(text, func(v MatchOf[V]) bool {
+		all = append(all, v)
+		return true
+	})
+	return all, err
 }
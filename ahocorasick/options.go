@@ -0,0 +1,128 @@
+package ahocorasick
+
+import "unicode/utf8"
+
+// MatchMode specifies semantics of matches which Matcher reports.
+type MatchMode int
+
+const (
+	// Standard reports all matches, including overlapping ones, in order of
+	// their end.
+	Standard MatchMode = iota
+
+	// LeftmostLongest reports non-overlapping matches from left to right.
+	// The longest one is preferred among matches which start at same index.
+	LeftmostLongest
+
+	// LeftmostFirst reports non-overlapping matches from left to right.
+	// The one added first is preferred among matches which start at same
+	// index.
+	LeftmostFirst
+)
+
+// Option configures Matcher on Compile.
+type Option func(*config)
+
+type config struct {
+	mode  MatchMode
+	dfa   bool
+	fold  Fold
+	bytes bool
+
+	boundary Boundary
+	track    Tracking
+}
+
+func newConfig(opts []Option) config {
+	var c config
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
+
+// WithMode returns an Option to specify MatchMode.
+func WithMode(mode MatchMode) Option {
+	return func(c *config) {
+		c.mode = mode
+	}
+}
+
+// WithDFA returns an Option to compile the matcher into a dense DFA.  It
+// makes matching faster with transition tables which consume more memory
+// than the trie.  Each distinct rune in patterns has its own class, since it
+// leads to different states, so the table takes states x (distinct runes +
+// 1) x 4 bytes.  It may be too large for dictionaries with thousands of
+// distinct runes like CJK ones, check Stats().TableBytes.
+func WithDFA() Option {
+	return func(c *config) {
+		c.dfa = true
+	}
+}
+
+// WithBytes returns an Option to match patterns and text byte by byte, so
+// patterns don't have to be valid UTF-8.  Each byte is treated as a rune in
+// matching, for example a byte is passed to Boundary as a rune.  Runes are
+// not folded in byte mode.
+func WithBytes() Option {
+	return func(c *config) {
+		c.bytes = true
+	}
+}
+
+// WithFold returns an Option to fold runes in both patterns and text before
+// comparing them.
+func WithFold(f Fold) Option {
+	return func(c *config) {
+		c.fold = f
+	}
+}
+
+// Tracking specifies positions of matches which are tracked in addition to
+// byte offsets.
+type Tracking int
+
+const (
+	// TrackRunes fills RuneIndex and RuneEnd of matches.
+	TrackRunes Tracking = 1 << iota
+
+	// TrackLines fills Line and Column of matches.  A line ends with "\n",
+	// so "\r\n" is also treated as a line break.
+	TrackLines
+)
+
+// WithTracking returns an Option to track positions of matches in runes or
+// lines.  They are tracked for both strings and readers.
+func WithTracking(t Tracking) Option {
+	return func(c *config) {
+		c.track = t
+	}
+}
+
+// validate checks a pattern can be compiled with the config.
+func (c *config) validate(pattern string) error {
+	if pattern == "" {
+		return ErrorEmptyPattern
+	}
+	if !c.bytes && !utf8.ValidString(pattern) {
+		return ErrorInvalidUTF8
+	}
+	return nil
+}
+
+// key returns a key of the pattern in the trie.
+func (c *config) key(pattern string) string {
+	if c.bytes {
+		return bytesKey(pattern)
+	}
+	return foldString(c.fold, pattern)
+}
+
+// bytesKey returns a string which has each byte of s as a rune.
+func bytesKey(s string) string {
+	rs := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		rs[i] = rune(s[i])
+	}
+	return string(rs)
+}
@@ -0,0 +1,166 @@
+package ahocorasick
+
+import (
+	"strings"
+	"testing"
+)
+
+func newModeMatcher(mode MatchMode, patterns ...string) *Matcher {
+	m := New()
+	for i, p := range patterns {
+		m.Add(p, i)
+	}
+	m.Compile(WithMode(mode))
+	return m
+}
+
+func TestLeftmostLongest(t *testing.T) {
+	m := newTestMatcher()
+	m.Compile(WithMode(LeftmostLongest))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "abcde", Value: 10},
+	}, matchAll(t, m, "abcde"))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ab", Value: 2},
+		{Index: 3, Pattern: "d", Value: 7},
+		{Index: 4, Pattern: "bab", Value: 6},
+	}, matchAll(t, m, "abcdbabc"))
+
+	m2 := newModeMatcher(LeftmostLongest, "abcd", "b", "ef", "abcdefgh")
+	assertMatches(t, []Match{
+		{Index: 1, Pattern: "b", Value: 1},
+		{Index: 4, Pattern: "ef", Value: 2},
+	}, matchAll(t, m2, "xbcdefgX"))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "abcd", Value: 0},
+		{Index: 4, Pattern: "ef", Value: 2},
+	}, matchAll(t, m2, "abcdefgX"))
+}
+
+func TestLeftmostFirst(t *testing.T) {
+	m := newTestMatcher()
+	m.Compile(WithMode(LeftmostFirst))
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ab", Value: 2},
+		{Index: 3, Pattern: "d", Value: 7},
+	}, matchAll(t, m, "abcde"))
+
+	m2 := newModeMatcher(LeftmostFirst, "Samwise", "Sam")
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "Samwise", Value: 0},
+		{Index: 8, Pattern: "Sam", Value: 1},
+	}, matchAll(t, m2, "Samwise Sam"))
+	m3 := newModeMatcher(LeftmostFirst, "Sam", "Samwise")
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "Sam", Value: 0},
+		{Index: 8, Pattern: "Sam", Value: 0},
+	}, matchAll(t, m3, "Samwise Sam"))
+}
+
+func TestLeftmostReader(t *testing.T) {
+	m := newModeMatcher(LeftmostLongest, "abcd", "b", "ef", "abcdefgh")
+	text := "abcdefgXxbcdefgX"
+	assertMatches(t, matchAll(t, m, text),
+		matchReaderAll(t, m, strings.NewReader(text)))
+}
+
+func assertPositions(t *testing.T, exp, act []Match) {
+	t.Helper()
+	if len(act) != len(exp) {
+		t.Fatalf("[]Match length is not %d (%d)", len(exp), len(act))
+	}
+	for i, e := range exp {
+		if act[i] != e {
+			t.Errorf("Match not matched at #%d\n  expected: %+v\n  actually: %+v", i, e, act[i])
+		}
+	}
+}
+
+func TestMatchEnd(t *testing.T) {
+	m := newModeMatcher(LeftmostLongest, "ab", "abcd", "ガ")
+	m.Compile(WithMode(LeftmostLongest), WithFold(FoldWidth))
+	assertPositions(t, []Match{
+		{Index: 1, Pattern: "abcd", Value: 1, End: 5},
+		{Index: 6, Pattern: "ab", Value: 0, End: 8},
+		{Index: 8, Pattern: "ガ", Value: 2, End: 14},
+	}, matchAll(t, m, "xabcdxabｶﾞ"))
+}
+
+func TestTracking(t *testing.T) {
+	m := New()
+	m.Add("gopher", 1)
+	m.Add("é", 2)
+	m.Add("go", 3)
+	m.Compile(WithFold(FoldWidth), WithTracking(TrackRunes|TrackLines))
+	text := "aé\r\nxé ｇｏpher\ngo"
+	exp := []Match{
+		{Index: 1, Pattern: "é", Value: 2, End: 3, RuneIndex: 1, RuneEnd: 2, Line: 1, Column: 2},
+		{Index: 6, Pattern: "é", Value: 2, End: 8, RuneIndex: 5, RuneEnd: 6, Line: 2, Column: 2},
+		{Index: 9, Pattern: "go", Value: 3, End: 15, RuneIndex: 7, RuneEnd: 9, Line: 2, Column: 4},
+		{Index: 9, Pattern: "gopher", Value: 1, End: 19, RuneIndex: 7, RuneEnd: 13, Line: 2, Column: 4},
+		{Index: 20, Pattern: "go", Value: 3, End: 22, RuneIndex: 14, RuneEnd: 16, Line: 3, Column: 1},
+	}
+	assertPositions(t, exp, matchAll(t, m, text))
+	assertPositions(t, exp, matchReaderAll(t, m, strings.NewReader(text)))
+
+	m.Compile(WithTracking(TrackLines))
+	assertPositions(t, []Match{
+		{Index: 0, Pattern: "é", Value: 2, End: 2, Line: 1, Column: 1},
+		{Index: 4, Pattern: "go", Value: 3, End: 6, Line: 2, Column: 1},
+	}, matchAll(t, m, "é\r\ngo"))
+}
+
+func TestTrackingParallel(t *testing.T) {
+	m := newTestMatcher()
+	m.Add("cat", 11, Bounded(WordBoundary))
+	text := randomText(50000, 3)
+	for _, opts := range [][]Option{
+		{WithTracking(TrackRunes | TrackLines)},
+		{WithTracking(TrackLines), WithMode(LeftmostLongest), WithFold(FoldWidth)},
+	} {
+		m.Compile(opts...)
+		exp := matchAll(t, m, text)
+		for _, n := range []int{1, 5} {
+			act, err := m.MatchParallel(text, n)
+			if err != nil {
+				t.Fatal("MatchParallel failed:", err)
+			}
+			assertPositions(t, exp, act)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	m := New()
+	m.Add("\xff\xfe", 1)
+	m.Add("é"[1:], 2)
+	m.Add("ab", 3)
+	m.Compile(WithBytes(), WithFold(FoldCase))
+	text := "a\xff\xfeb é AB ab"
+	exp := []Match{
+		{Index: 1, Pattern: "\xff\xfe", Value: 1, End: 3},
+		{Index: 6, Pattern: "é"[1:], Value: 2, End: 7},
+		{Index: 11, Pattern: "ab", Value: 3, End: 13},
+	}
+	assertPositions(t, exp, matchAll(t, m, text))
+	assertPositions(t, exp, matchReaderAll(t, m, strings.NewReader(text)))
+	if s, exp := replaceAll(t, m, text), "a1b \xc32 AB 3"; s != exp {
+		t.Errorf("ReplaceAll returns %q, expected %q", s, exp)
+	}
+	if s := m.Stats(); s.MaxDepth != 2 {
+		t.Errorf("MaxDepth should be 2 in bytes: %+v", s)
+	}
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal("MarshalBinary failed:", err)
+	}
+	m2 := New()
+	if err := m2.UnmarshalBinary(data); err != nil {
+		t.Fatal("UnmarshalBinary failed:", err)
+	}
+	assertPositions(t, exp, matchAll(t, m2, text))
+	if s1, s2 := m.Stats(), m2.Stats(); s1 != s2 {
+		t.Errorf("Stats are not restored: %+v, expected %+v", s2, s1)
+	}
+}
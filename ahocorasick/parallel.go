@@ -0,0 +1,161 @@
+package ahocorasick
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// minChunkSize is the minimum size of chunks for MatchParallel in bytes.
+const minChunkSize = 4096
+
+type chunkResult[V any] struct {
+	from, to int
+	// pos is the position of the rune at from, it is used when positions
+	// are tracked.
+	pos     textPos
+	matches []MatchOf[V]
+	err     error
+}
+
+// MatchParallel finds all matches in text with workers goroutines, and
+// returns them in same order as Each.  The text is split into chunks at rune
+// boundaries, each chunk is scanned with the runes around it so matches
+// which cross chunks are found once.  When workers is zero or less,
+// runtime.GOMAXPROCS(0) is used.
+func (m *MatcherOf[V]) MatchParallel(text string, workers int) ([]MatchOf[V], error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	size := len(text) / workers
+	if size < minChunkSize {
+		size = minChunkSize
+	}
+	var chunks []*chunkResult[V]
+	for from := 0; from < len(text); {
+		to := runeStartAt(text, from+size)
+		chunks = append(chunks, &chunkResult[V]{from: from, to: to})
+		from = to
+	}
+	if m.conf.track != 0 {
+		locateChunks(text, chunks)
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range chunks {
+		wg.Add(1)
+		go func(c *chunkResult[V]) {
+			defer wg.Done()
+			c.matches, c.err = m.scanChunk(text, c.from, c.to, c.pos)
+		}(c)
+	}
+	wg.Wait()
+
+	var all []MatchOf[V]
+	last := 0
+	for _, c := range chunks {
+		if c.err != nil {
+			return nil, c.err
+		}
+		if m.conf.mode != Standard && last > c.from {
+			// a match in previous chunks overlaps this chunk, so scan it
+			// again after the match.
+			if last >= c.to {
+				continue
+			}
+			ms, err := m.scanChunk(text, last, c.to, c.pos.advance(text[c.from:last]))
+			if err != nil {
+				return nil, err
+			}
+			c.matches = ms
+		}
+		all = append(all, c.matches...)
+		if n := len(all); n > 0 {
+			last = all[n-1].End
+		}
+	}
+	if m.conf.mode == Standard {
+		sort.SliceStable(all, func(i, j int) bool {
+			return all[i].End < all[j].End
+		})
+	}
+	return all, nil
+}
+
+// locateChunks fills positions of chunks in runes and lines.
+func locateChunks[V any](text string, chunks []*chunkResult[V]) {
+	deltas := make([]textPos, len(chunks))
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		go func(i int, c *chunkResult[V]) {
+			defer wg.Done()
+			deltas[i] = textPos{}.advance(text[c.from:c.to])
+		}(i, c)
+	}
+	wg.Wait()
+	p := textPos{line: 1, col: 1}
+	for i, c := range chunks {
+		c.pos = p
+		p = p.add(deltas[i])
+	}
+}
+
+// scanChunk finds matches which start in text[from:to].  pos is the position
+// of the rune at from.
+func (m *MatcherOf[V]) scanChunk(text string, from, to int, pos textPos) ([]MatchOf[V], error) {
+	s, err := m.newScanner()
+	if err != nil {
+		return nil, err
+	}
+	// start at a rune before the chunk, for folding and boundaries.
+	start := from
+	if start > 0 {
+		r, n := utf8.DecodeLastRuneInString(text[:start])
+		start -= n
+		// the position of the rune is not correct, but no matches which
+		// start there are reported.
+		if r == '\n' {
+			s.cursor = textPos{rune: pos.rune - 1, line: pos.line - 1}
+		} else {
+			s.cursor = textPos{rune: pos.rune - 1, line: pos.line, col: pos.col - 1}
+		}
+	}
+	// end after enough runes to complete matches which start in the chunk.
+	end := to
+	for i := 0; i < 2*m.maxDepth+2 && end < len(text); i++ {
+		_, n := utf8.DecodeRuneInString(text[end:])
+		end += n
+	}
+	s.end = from - start
+	var matches []MatchOf[V]
+	scanString(s, text[:end][start:], func(v MatchOf[V]) bool {
+		v.Index += start
+		v.End += start
+		if v.Index < from {
+			return true
+		}
+		if v.Index >= to {
+			// non-overlapping matches are reported in order of index.
+			return s.mode == Standard
+		}
+		matches = append(matches, v)
+		return true
+	})
+	return matches, nil
+}
+
+// runeStartAt returns the first index of a rune at i or after it.
+func runeStartAt(text string, i int) int {
+	if i >= len(text) {
+		return len(text)
+	}
+	for i < len(text) && !utf8.RuneStart(text[i]) {
+		i++
+	}
+	return i
+}
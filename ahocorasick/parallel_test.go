@@ -0,0 +1,64 @@
+package ahocorasick
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func randomText(n int, seed int64) string {
+	words := []string{"ab", "bc", "bab", "d", "abcde", "ｶﾞ", "ガイド", "x", " ", "\n", "Cat", "cat"}
+	rnd := rand.New(rand.NewSource(seed))
+	var b strings.Builder
+	for b.Len() < n {
+		b.WriteString(words[rnd.Intn(len(words))])
+	}
+	return b.String()
+}
+
+func TestMatchParallel(t *testing.T) {
+	m := newTestMatcher()
+	m.Add("ガイド", 11)
+	m.Add("cat", 12, Bounded(WordBoundary))
+	m.Add("abab", 13)
+	text := randomText(100000, 1)
+	for _, opts := range [][]Option{
+		{},
+		{WithDFA()},
+		{WithMode(LeftmostLongest)},
+		{WithMode(LeftmostFirst), WithFold(FoldCase | FoldWidth)},
+		{WithMode(LeftmostLongest), WithBoundary(WordBoundary)},
+	} {
+		m.Compile(opts...)
+		exp := matchAll(t, m, text)
+		for _, n := range []int{1, 3, 8} {
+			act, err := m.MatchParallel(text, n)
+			if err != nil {
+				t.Fatal("MatchParallel failed:", err)
+			}
+			assertMatches(t, exp, act)
+		}
+	}
+}
+
+func TestConcurrentReaders(t *testing.T) {
+	m := newTestMatcher()
+	m.Compile(WithMode(LeftmostLongest), WithFold(FoldCase))
+	text := randomText(10000, 2)
+	exp := matchAll(t, m, text)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assertMatches(t, exp, matchAll(t, m, text))
+			act, err := m.MatchParallel(text, 4)
+			if err != nil {
+				t.Error("MatchParallel failed:", err)
+			}
+			assertMatches(t, exp, act)
+		}()
+	}
+	wg.Wait()
+}
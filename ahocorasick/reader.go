@@ -0,0 +1,57 @@
+package ahocorasick
+
+import (
+	"bufio"
+	"io"
+)
+
+// MatchReader scans text read from rd and calls proc for each match.
+// Index of Match is an absolute byte offset in the stream.  Scanning stops
+// when proc returns false.
+func (m *MatcherOf[V]) MatchReader(rd io.Reader, proc func(MatchOf[V]) bool) error {
+	s, err := m.newScanner()
+	if err != nil {
+		return err
+	}
+	next := runeReader(rd)
+	if s.bytes {
+		next = byteReader(rd)
+	}
+	idx := 0
+	for {
+		r, n, err := next()
+		if err == io.EOF {
+			s.finish(proc)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !s.step(r, idx, n, proc) {
+			return nil
+		}
+		idx += n
+	}
+}
+
+func runeReader(rd io.Reader) func() (rune, int, error) {
+	rr, ok := rd.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(rd)
+	}
+	return rr.ReadRune
+}
+
+// byteReader returns a function which reads a byte as a rune.
+func byteReader(rd io.Reader) func() (rune, int, error) {
+	br, ok := rd.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(rd)
+	}
+	return func() (rune, int, error) {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, 0, err
+		}
+		return rune(b), 1, nil
+	}
+}
@@ -0,0 +1,76 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func matchReaderAll(t *testing.T, m *Matcher, rd io.Reader) []Match {
+	var all []Match
+	err := m.MatchReader(rd, func(v Match) bool {
+		all = append(all, v)
+		return true
+	})
+	if err != nil {
+		t.Fatal("MatchReader failed:", err)
+	}
+	return all
+}
+
+func TestMatchReader(t *testing.T) {
+	m := newTestMatcher()
+	r1 := matchReaderAll(t, m, strings.NewReader("abcde"))
+	assertMatches(t, matchAll(t, m, "abcde"), r1)
+}
+
+func TestMatchReaderSplitRunes(t *testing.T) {
+	m := New()
+	m.Add("日本", 1)
+	m.Add("本語", 2)
+	m.Add("語x", 3)
+	m.Compile()
+	text := "xx日本語xyz日本"
+	rd := iotest.OneByteReader(bytes.NewReader([]byte(text)))
+	r1 := matchReaderAll(t, m, rd)
+	assertMatches(t, matchAll(t, m, text), r1)
+	assertMatches(t, []Match{
+		{Index: 2, Pattern: "日本", Value: 1},
+		{Index: 5, Pattern: "本語", Value: 2},
+		{Index: 8, Pattern: "語x", Value: 3},
+		{Index: 14, Pattern: "日本", Value: 1},
+	}, r1)
+}
+
+func TestMatchReaderStop(t *testing.T) {
+	m := newTestMatcher()
+	count := 0
+	err := m.MatchReader(strings.NewReader("abcdeabcde"), func(Match) bool {
+		count++
+		return count < 2
+	})
+	if err != nil {
+		t.Fatal("MatchReader failed:", err)
+	}
+	if count != 2 {
+		t.Errorf("proc called %d times, expected 2", count)
+	}
+}
+
+func TestMatchReaderError(t *testing.T) {
+	m := newTestMatcher()
+	errTest := errors.New("test error")
+	rd := io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(errTest))
+	var all []Match
+	err := m.MatchReader(rd, func(v Match) bool {
+		all = append(all, v)
+		return true
+	})
+	if err != errTest {
+		t.Errorf("unexpected error: %v", err)
+	}
+	assertMatches(t, []Match{{Index: 0, Pattern: "ab", Value: 2}}, all)
+}
@@ -0,0 +1,96 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Mask returns a replacement function for Replace and others, which masks a
+// match with s repeated as many times as runes of its pattern.
+func Mask[V any](s string) func(MatchOf[V]) string {
+	return func(v MatchOf[V]) string {
+		return strings.Repeat(s, utf8.RuneCountInString(v.Pattern))
+	}
+}
+
+// ReplaceWriter is an io.WriteCloser which replaces non-overlapping matches
+// in written text like Replace, then writes the result to the underlying
+// writer.  It keeps only text which may be a part of matches, so Close must
+// be called to write the rest.
+type ReplaceWriter[V any] struct {
+	r      *replacer[V]
+	closed bool
+}
+
+// NewWriter returns a ReplaceWriter which writes to w.  repl is same as
+// ReplaceAllFunc.
+func (m *MatcherOf[V]) NewWriter(w io.Writer, repl func(MatchOf[V]) string) (*ReplaceWriter[V], error) {
+	r, err := m.newReplacer(w, repl)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplaceWriter[V]{r: r}, nil
+}
+
+// Write implements io.Writer.  It returns an error of the underlying writer
+// and the error is sticky.
+func (w *ReplaceWriter[V]) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrorClosed
+	}
+	if err := w.r.write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the rest of text.  It doesn't close the underlying writer.
+func (w *ReplaceWriter[V]) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.r.close()
+}
+
+// ReplaceReader is an io.Reader which reads text from the underlying reader,
+// and replaces non-overlapping matches in it like Replace.
+type ReplaceReader[V any] struct {
+	rd    io.Reader
+	r     *replacer[V]
+	out   bytes.Buffer
+	chunk []byte
+	err   error
+}
+
+// NewReader returns a ReplaceReader which reads from rd.  repl is same as
+// ReplaceAllFunc.
+func (m *MatcherOf[V]) NewReader(rd io.Reader, repl func(MatchOf[V]) string) (*ReplaceReader[V], error) {
+	rr := &ReplaceReader[V]{rd: rd, chunk: make([]byte, 4096)}
+	r, err := m.newReplacer(&rr.out, repl)
+	if err != nil {
+		return nil, err
+	}
+	rr.r = r
+	return rr, nil
+}
+
+// Read implements io.Reader.
+func (rr *ReplaceReader[V]) Read(p []byte) (int, error) {
+	for rr.out.Len() == 0 && rr.err == nil {
+		n, err := rr.rd.Read(rr.chunk)
+		rr.r.write(rr.chunk[:n])
+		if err == io.EOF {
+			rr.r.close()
+			rr.err = io.EOF
+		} else if err != nil {
+			rr.err = err
+		}
+	}
+	if rr.out.Len() > 0 {
+		return rr.out.Read(p)
+	}
+	return 0, rr.err
+}
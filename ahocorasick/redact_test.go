@@ -0,0 +1,99 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReplaceWriter(t *testing.T) {
+	m := newReplaceMatcher()
+	text := strings.Repeat("a cat and ネコ, categorycat\xff secret\n", 300)
+	var b bytes.Buffer
+	w, err := m.NewWriter(&b, nil)
+	if err != nil {
+		t.Fatal("NewWriter failed:", err)
+	}
+	for i := 0; i < len(text); i += 7 {
+		end := min(i+7, len(text))
+		if n, err := w.Write([]byte(text[i:end])); err != nil || n != end-i {
+			t.Fatalf("Write failed: n=%d err=%v", n, err)
+		}
+	}
+	if b.Len() >= len(text) {
+		t.Errorf("all of text are written before Close")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("Close failed:", err)
+	}
+	if s, exp := b.String(), replaceAll(t, m, text); s != exp {
+		t.Errorf("ReplaceWriter outputs %q, expected %q", s, exp)
+	}
+	if _, err := w.Write([]byte("cat")); err != ErrorClosed {
+		t.Errorf("Write after Close should fail with ErrorClosed: %v", err)
+	}
+}
+
+func TestReplaceWriterBuffer(t *testing.T) {
+	m := newReplaceMatcher()
+	var b bytes.Buffer
+	w, _ := m.NewWriter(&b, Mask[any]("*"))
+	w.Write([]byte("my secret is cat"))
+	// "cat" may be a part of "category".
+	if s, exp := b.String(), "my ****** is "; s != exp {
+		t.Errorf("written before Close %q, expected %q", s, exp)
+	}
+	w.Close()
+	if s, exp := b.String(), "my ****** is ***"; s != exp {
+		t.Errorf("written %q, expected %q", s, exp)
+	}
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestReplaceWriterError(t *testing.T) {
+	m := newReplaceMatcher()
+	w, _ := m.NewWriter(errorWriter{}, nil)
+	_, err := w.Write([]byte("a cat and a dog"))
+	if err == nil {
+		t.Fatal("Write should fail")
+	}
+	if _, err2 := w.Write([]byte("x")); err2 != err {
+		t.Errorf("error is not sticky: %v", err2)
+	}
+}
+
+func TestReplaceReader(t *testing.T) {
+	m := newReplaceMatcher()
+	text := strings.Repeat("a cat and ネコ, categorycat\xff secret\n", 300)
+	rd, err := m.NewReader(iotest.HalfReader(strings.NewReader(text)), nil)
+	if err != nil {
+		t.Fatal("NewReader failed:", err)
+	}
+	b, err := io.ReadAll(iotest.OneByteReader(rd))
+	if err != nil {
+		t.Fatal("ReadAll failed:", err)
+	}
+	if s, exp := string(b), replaceAll(t, m, text); s != exp {
+		t.Errorf("ReplaceReader outputs %q, expected %q", s, exp)
+	}
+}
+
+func TestReplaceReaderError(t *testing.T) {
+	m := newReplaceMatcher()
+	rd, _ := m.NewReader(iotest.TimeoutReader(strings.NewReader("secret cat")), Mask[any]("x"))
+	b, err := io.ReadAll(rd)
+	if err != iotest.ErrTimeout {
+		t.Errorf("ReadAll should fail with ErrTimeout: %v", err)
+	}
+	if s := string(b); s != "xxxxxx " {
+		t.Errorf("unexpected output before error: %q", s)
+	}
+}
@@ -0,0 +1,180 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReplaceAll returns a copy of text, replacing non-overlapping matches with
+// their values.  See ReplaceAllFunc for details.
+func (m *MatcherOf[V]) ReplaceAll(text string) (string, error) {
+	return m.ReplaceAllFunc(text, nil)
+}
+
+// ReplaceAllFunc returns a copy of text, replacing non-overlapping matches
+// with the return value of repl.  When the matcher is compiled with Standard
+// mode, matches are chosen as LeftmostLongest.  When repl is nil, the value
+// of the match is used as the replacement: string and []byte are used as is,
+// nil removes the match and others are formatted with fmt.Sprint.  It
+// returns ErrorNotCompiled or an error of compiling changed patterns without
+// replacing.
+func (m *MatcherOf[V]) ReplaceAllFunc(text string, repl func(MatchOf[V]) string) (string, error) {
+	if repl == nil {
+		repl = valueString[V]
+	}
+	s, err := m.newReplaceScanner()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	last := 0
+	proc := func(v MatchOf[V]) bool {
+		b.WriteString(text[last:v.Index])
+		b.WriteString(repl(v))
+		last = v.End
+		return true
+	}
+	scanString(s, text, proc)
+	if last == 0 {
+		return text, nil
+	}
+	b.WriteString(text[last:])
+	return b.String(), nil
+}
+
+// Replace copies text from rd to w, replacing non-overlapping matches like
+// ReplaceAllFunc.  It keeps only text which may be a part of matches in
+// memory.
+func (m *MatcherOf[V]) Replace(w io.Writer, rd io.Reader, repl func(MatchOf[V]) string) error {
+	r, err := m.newReplacer(w, repl)
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, 4096)
+	for {
+		n, err := rd.Read(chunk)
+		if werr := r.write(chunk[:n]); werr != nil {
+			return werr
+		}
+		if err == io.EOF {
+			return r.close()
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// replacer replaces matches in a stream which is given piece by piece, and
+// writes the result to w.
+type replacer[V any] struct {
+	s    *scanner[V]
+	w    io.Writer
+	repl func(MatchOf[V]) string
+	buf  []byte // bytes which have not been written yet.
+	base int    // offset of buf[0] in the stream.
+	next int    // index of next rune in buf.
+	err  error
+}
+
+func (m *MatcherOf[V]) newReplacer(w io.Writer, repl func(MatchOf[V]) string) (*replacer[V], error) {
+	if repl == nil {
+		repl = valueString[V]
+	}
+	s, err := m.newReplaceScanner()
+	if err != nil {
+		return nil, err
+	}
+	return &replacer[V]{s: s, w: w, repl: repl}, nil
+}
+
+// proc writes text before a match and the replacement of the match.
+func (r *replacer[V]) proc(v MatchOf[V]) bool {
+	if _, r.err = r.w.Write(r.buf[:v.Index-r.base]); r.err != nil {
+		return false
+	}
+	if _, r.err = io.WriteString(r.w, r.repl(v)); r.err != nil {
+		return false
+	}
+	r.discard(v.End - r.base)
+	return true
+}
+
+func (r *replacer[V]) discard(n int) {
+	r.buf = r.buf[n:]
+	r.base += n
+	r.next -= n
+}
+
+// write scans p, and writes text which can't be a part of matches.
+func (r *replacer[V]) write(p []byte) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.buf = append(r.buf, p...)
+	for r.next < len(r.buf) && (r.s.bytes || utf8.FullRune(r.buf[r.next:])) {
+		if !r.step() {
+			return r.err
+		}
+	}
+	if n := r.s.pos() - r.base; n > 0 {
+		if _, r.err = r.w.Write(r.buf[:n]); r.err != nil {
+			return r.err
+		}
+		r.discard(n)
+	}
+	return nil
+}
+
+func (r *replacer[V]) step() bool {
+	c, size := decode(r.s.bytes, r.buf[r.next:])
+	if !r.s.step(c, r.base+r.next, size, r.proc) {
+		return false
+	}
+	r.next += size
+	return true
+}
+
+// close scans rest of the stream as its end, and writes all of them.
+func (r *replacer[V]) close() error {
+	if r.err != nil {
+		return r.err
+	}
+	for r.next < len(r.buf) {
+		if !r.step() {
+			return r.err
+		}
+	}
+	if !r.s.finish(r.proc) {
+		return r.err
+	}
+	if _, r.err = r.w.Write(r.buf); r.err != nil {
+		return r.err
+	}
+	r.discard(len(r.buf))
+	return nil
+}
+
+func (m *MatcherOf[V]) newReplaceScanner() (*scanner[V], error) {
+	s, err := m.newScanner()
+	if err != nil {
+		return nil, err
+	}
+	if s.mode == Standard {
+		s.mode = LeftmostLongest
+	}
+	return s, nil
+}
+
+func valueString[V any](v MatchOf[V]) string {
+	switch x := any(v.Value).(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	}
+	return fmt.Sprint(v.Value)
+}
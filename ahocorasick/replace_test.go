@@ -0,0 +1,75 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func newReplaceMatcher() *Matcher {
+	m := New()
+	m.Add("cat", "dog")
+	m.Add("category", "kind")
+	m.Add("ネコ", "イヌ")
+	m.Add("secret", nil)
+	m.Add("one", 1)
+	m.Compile()
+	return m
+}
+
+// replaceAll returns text replaced by ReplaceAll, it fails t on errors.
+func replaceAll[V any](t *testing.T, m *MatcherOf[V], text string) string {
+	t.Helper()
+	return replaceAllFunc(t, m, text, nil)
+}
+
+// replaceAllFunc returns text replaced by ReplaceAllFunc, it fails t on
+// errors.
+func replaceAllFunc[V any](t *testing.T, m *MatcherOf[V], text string, repl func(MatchOf[V]) string) string {
+	t.Helper()
+	s, err := m.ReplaceAllFunc(text, repl)
+	if err != nil {
+		t.Fatal("ReplaceAllFunc failed:", err)
+	}
+	return s
+}
+
+func TestReplaceAll(t *testing.T) {
+	m := newReplaceMatcher()
+	for _, c := range []struct{ in, out string }{
+		{"", ""},
+		{"nothing", "nothing"},
+		{"cat", "dog"},
+		{"a cat and a category", "a dog and a kind"},
+		{"ネコとcat", "イヌとdog"},
+		{"my secret is one", "my  is 1"},
+	} {
+		if s := replaceAll(t, m, c.in); s != c.out {
+			t.Errorf("ReplaceAll(%q) returns %q, expected %q", c.in, s, c.out)
+		}
+	}
+}
+
+func TestReplaceAllFunc(t *testing.T) {
+	m := newTestMatcher()
+	s := replaceAllFunc(t, m, "xabcdex bab abc", func(v Match) string {
+		return "<" + strings.ToUpper(v.Pattern) + ">"
+	})
+	if exp := "x<ABCDE>x <BAB> <AB>c"; s != exp {
+		t.Errorf("ReplaceAllFunc returns %q, expected %q", s, exp)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	m := newReplaceMatcher()
+	text := strings.Repeat("a cat and ネコ, categorycat\xff secret\n", 300)
+	var b bytes.Buffer
+	err := m.Replace(&b, iotest.OneByteReader(strings.NewReader(text)), nil)
+	if err != nil {
+		t.Fatal("Replace failed:", err)
+	}
+	if s, exp := b.String(), replaceAll(t, m, text); s != exp {
+		t.Errorf("Replace outputs %q, expected %q", s, exp)
+	}
+}
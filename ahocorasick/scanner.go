@@ -0,0 +1,530 @@
+package ahocorasick
+
+import (
+	"unicode/utf8"
+
+	"github.com/koron/gelatin/trie"
+)
+
+// scanString scans whole text with s.
+func scanString[V any](s *scanner[V], text string, proc func(MatchOf[V]) bool) bool {
+	for i := 0; i < len(text); {
+		r, n := decodeString(s.bytes, text[i:])
+		if !s.step(r, i, n, proc) {
+			return false
+		}
+		i += n
+	}
+	return s.finish(proc)
+}
+
+// decodeString returns the first rune in text and its size.  Each byte is a
+// rune in byte mode.
+func decodeString(bytes bool, text string) (rune, int) {
+	if bytes {
+		return rune(text[0]), 1
+	}
+	return utf8.DecodeRuneInString(text)
+}
+
+// decode works like decodeString for b.
+func decode(bytes bool, b []byte) (rune, int) {
+	if bytes {
+		return rune(b[0]), 1
+	}
+	return utf8.DecodeRune(b)
+}
+
+// scanner keeps state of the automaton between input runes.
+type scanner[V any] struct {
+	root, curr *trie.TernaryNode
+	dfa        *dfa[V]
+	state      int32
+	mode       MatchMode
+	bytes      bool
+	folder     *folder
+	// starts keeps start indexes of recent runes as a ring buffer.
+	starts []int
+	count  int
+	// pending keeps candidates of non-overlapping matches.
+	pending []candidate[V]
+	// end is end of the last reported non-overlapping match.
+	end int
+	// start is start of the label sequence of the current node.
+	start int
+
+	// recent is true when recent runes of the text are kept.
+	recent bool
+	// runes keeps recent runes of the text as a ring buffer.
+	runes [3]textRune
+	nrune int
+
+	// fields for boundaries.
+	bounded  bool
+	boundary Boundary
+	// prevs keeps runes just before recent runes, like starts.
+	prevs []rune
+	eof   bool
+	// waiting keeps candidates which wait for the next rune.
+	waiting []waitingCandidate[V]
+
+	// fields for tracking positions.
+	track Tracking
+	// cursor is the position of the next rune of the text.
+	cursor textPos
+	// positions keeps positions of recent runes, like starts.
+	positions []textPos
+
+	// groups is the set of enabled groups.
+	groups GroupSet
+
+	// fields for wildcard patterns.
+	globbed bool
+	// lookback is the longest prefix of wildcard patterns in runes.
+	lookback int
+	// labels keeps recent folded runes, like starts.
+	labels []rune
+	// globWaits keeps wildcard patterns which wait for their suffixes.
+	globWaits []globWait[V]
+}
+
+// globWait is a wildcard pattern whose suffix is being matched.  first is
+// the count of runes before the match.
+type globWait[V any] struct {
+	data   *nodeData[V]
+	first  int
+	states []globState
+}
+
+type candidate[V any] struct {
+	MatchOf[V]
+	id   int
+	dups []*nodeData[V]
+}
+
+type textRune struct {
+	r          rune
+	start, end int
+	pos        textPos
+}
+
+// textPos is a position of a rune in runes and lines.
+type textPos struct {
+	rune, line, col int
+}
+
+// next returns the position of a rune next to r which is placed at p.
+func (p textPos) next(r rune) textPos {
+	p.rune++
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return p
+}
+
+// advance returns the position after text which starts at p.
+func (p textPos) advance(text string) textPos {
+	for _, r := range text {
+		p = p.next(r)
+	}
+	return p
+}
+
+// add returns the position after text which starts at p, when d is the
+// position after the text which starts at zero.
+func (p textPos) add(d textPos) textPos {
+	p.rune += d.rune
+	if d.line > 0 {
+		p.line += d.line
+		p.col = d.col
+	} else {
+		p.col += d.col
+	}
+	return p
+}
+
+type waitingCandidate[V any] struct {
+	candidate[V]
+	prev     rune
+	boundary Boundary
+}
+
+func (m *MatcherOf[V]) newScanner() (*scanner[V], error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	root := m.trie.Root().(*trie.TernaryNode)
+	s := &scanner[V]{
+		root:   root,
+		curr:   root,
+		dfa:    m.dfa,
+		mode:   m.conf.mode,
+		bytes:  m.conf.bytes,
+		starts: make([]int, m.maxDepth+m.lookback+1),
+		groups: AllGroups,
+	}
+	if m.globbed {
+		s.globbed = true
+		s.lookback = m.lookback
+		s.labels = make([]rune, len(s.starts))
+	}
+	if m.bounded {
+		s.bounded = true
+		s.boundary = m.conf.boundary
+		s.prevs = make([]rune, len(s.starts))
+	}
+	if m.conf.track != 0 {
+		s.track = m.conf.track
+		s.cursor = textPos{line: 1, col: 1}
+		s.positions = make([]textPos, len(s.starts))
+	}
+	s.recent = s.bounded || s.track != 0
+	if m.conf.fold != 0 && !m.conf.bytes {
+		s.folder = &folder{fold: m.conf.fold}
+	}
+	return s, nil
+}
+
+// step moves the automaton by a rune r placed at idx with size bytes, and
+// calls proc for each match which is determined.  It returns false when
+// proc returns false.
+func (s *scanner[V]) step(r rune, idx, size int, proc func(MatchOf[V]) bool) bool {
+	if s.recent {
+		s.runes[s.nrune%len(s.runes)] = textRune{r: r, start: idx, end: idx + size, pos: s.cursor}
+		s.nrune++
+		s.cursor = s.cursor.next(r)
+	}
+	if s.bounded && !s.resolve(r, proc) {
+		return false
+	}
+	if s.folder == nil {
+		return s.advance(r, idx, idx+size, proc)
+	}
+	return s.folder.feed(r, idx, idx+size, func(r rune, start, end int) bool {
+		return s.advance(r, start, end, proc)
+	})
+}
+
+// advance moves the automaton by a (folded) rune r which is placed between
+// start and end in the text.
+func (s *scanner[V]) advance(r rune, start, end int, proc func(MatchOf[V]) bool) bool {
+	s.starts[s.count%len(s.starts)] = start
+	if s.bounded {
+		s.prevs[s.count%len(s.prevs)] = s.runeBefore(start)
+	}
+	if s.track != 0 {
+		s.positions[s.count%len(s.positions)] = s.positionAt(start)
+	}
+	if s.globbed {
+		s.labels[s.count%len(s.labels)] = r
+	}
+	s.count++
+	if len(s.globWaits) > 0 && !s.stepGlobs(r, end, proc) {
+		return false
+	}
+	var depth int
+	if s.dfa != nil {
+		s.state = s.dfa.next(s.state, r)
+		for _, d := range s.dfa.outputs(s.state) {
+			if !s.emit(d, end, proc) {
+				return false
+			}
+		}
+		depth = s.dfa.depth[s.state]
+	} else {
+		s.curr = getNextNode[V](s.curr, s.root, r)
+		if s.curr != s.root && !fireAll(s.curr, s.root, func(d *nodeData[V]) bool {
+			return s.emit(d, end, proc)
+		}) {
+			return false
+		}
+		depth = getNodeData[V](s.curr).depth
+	}
+	// no matches which will be found later can start before "start".
+	s.start = end
+	if n := min(depth+s.lookback, s.count); n > 0 {
+		s.start = s.startOf(n)
+	}
+	if s.mode == Standard {
+		return true
+	}
+	return s.flush(s.start, proc)
+}
+
+// startOf returns start index of the sequence of recent n runes.
+func (s *scanner[V]) startOf(n int) int {
+	return s.starts[(s.count-n)%len(s.starts)]
+}
+
+// emit reports matches for d which end at end, or keeps them as candidates.
+// Wildcard patterns in d start to match their prefixes and suffixes.
+func (s *scanner[V]) emit(d *nodeData[V], end int, proc func(MatchOf[V]) bool) bool {
+	if d.pattern != nil && !s.emitAt(d, s.count-d.depth, end, proc) {
+		return false
+	}
+	for _, g := range d.globs {
+		if !s.startGlob(g, end, proc) {
+			return false
+		}
+	}
+	return true
+}
+
+// emitAt reports a match for d which starts at the rune after first runes
+// and ends at end, or keeps it as a candidate.
+func (s *scanner[V]) emitAt(d *nodeData[V], first, end int, proc func(MatchOf[V]) bool) bool {
+	head, dups := d, d.dups
+	if s.groups != AllGroups {
+		if head, dups = s.filter(d); head == nil {
+			return true
+		}
+	}
+	c := candidate[V]{
+		MatchOf: MatchOf[V]{
+			Index:   s.starts[first%len(s.starts)],
+			Pattern: *head.pattern,
+			Value:   head.value,
+			End:     end,
+			Groups:  head.groups,
+		},
+		id:   head.id,
+		dups: dups,
+	}
+	if s.track != 0 {
+		s.locate(&c.MatchOf, s.positions[first%len(s.positions)])
+	}
+	if s.bounded {
+		b := d.boundary
+		if b == nil {
+			b = s.boundary
+		}
+		if b != nil {
+			prev := s.prevs[first%len(s.prevs)]
+			next, ok := s.runeAfter(end)
+			if !ok {
+				s.waiting = append(s.waiting, waitingCandidate[V]{
+					candidate: c,
+					prev:      prev,
+					boundary:  b,
+				})
+				return true
+			}
+			if !b(prev, next) {
+				return true
+			}
+		}
+	}
+	return s.accept(c, proc)
+}
+
+// startGlob matches the prefix of a wildcard pattern d with recent runes,
+// and starts to match its suffix.  The anchor of d ends at end.
+func (s *scanner[V]) startGlob(d *nodeData[V], end int, proc func(MatchOf[V]) bool) bool {
+	if s.groups != AllGroups && !s.enabled(d) {
+		return true
+	}
+	first, ok := d.glob.matchPrefix(s.count-d.depth, func(i int) rune {
+		return s.labels[i%len(s.labels)]
+	})
+	if !ok {
+		return true
+	}
+	states, done := d.glob.begin()
+	if done {
+		return s.emitAt(d, first, end, proc)
+	}
+	s.globWaits = append(s.globWaits, globWait[V]{data: d, first: first, states: states})
+	return true
+}
+
+// stepGlobs moves waiting wildcard patterns by a folded rune r which ends
+// at end.
+func (s *scanner[V]) stepGlobs(r rune, end int, proc func(MatchOf[V]) bool) bool {
+	waits := s.globWaits
+	s.globWaits = s.globWaits[:0]
+	for _, w := range waits {
+		states, done := w.data.glob.step(w.states, r)
+		if done {
+			if !s.emitAt(w.data, w.first, end, proc) {
+				return false
+			}
+			continue
+		}
+		if len(states) > 0 {
+			w.states = states
+			s.globWaits = append(s.globWaits, w)
+		}
+	}
+	return true
+}
+
+// accept reports a match or keeps it as a candidate of non-overlapping
+// matches.
+func (s *scanner[V]) accept(c candidate[V], proc func(MatchOf[V]) bool) bool {
+	if s.mode == Standard {
+		return s.report(c, proc)
+	}
+	if c.Index >= s.end {
+		s.pending = append(s.pending, c)
+	}
+	return true
+}
+
+// report reports a match of c and ones of duplicated patterns.
+func (s *scanner[V]) report(c candidate[V], proc func(MatchOf[V]) bool) bool {
+	if !proc(c.MatchOf) {
+		return false
+	}
+	for _, d := range c.dups {
+		v := c.MatchOf
+		v.Pattern, v.Value, v.Groups = *d.pattern, d.value, d.groups
+		if !proc(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// locate fills tracked positions of v which starts at p.
+func (s *scanner[V]) locate(v *MatchOf[V], p textPos) {
+	if s.track&TrackRunes != 0 {
+		v.RuneIndex = p.rune
+		v.RuneEnd = s.positionAt(v.End).rune
+	}
+	if s.track&TrackLines != 0 {
+		v.Line, v.Column = p.line, p.col
+	}
+}
+
+// positionAt returns the position of a rune in the text which starts at idx.
+// The rune may be not read yet when it is just after recent runes.
+func (s *scanner[V]) positionAt(idx int) textPos {
+	for i := 1; i <= len(s.runes) && i <= s.nrune; i++ {
+		if t := s.runes[(s.nrune-i)%len(s.runes)]; t.start == idx {
+			return t.pos
+		} else if t.end == idx {
+			return t.pos.next(t.r)
+		}
+	}
+	return s.cursor
+}
+
+// resolve checks boundaries of waiting candidates with the next rune.
+func (s *scanner[V]) resolve(next rune, proc func(MatchOf[V]) bool) bool {
+	waiting := s.waiting
+	s.waiting = s.waiting[:0]
+	for _, w := range waiting {
+		if w.boundary(w.prev, next) && !s.accept(w.candidate, proc) {
+			return false
+		}
+	}
+	return true
+}
+
+// runeBefore returns a rune in the text which ends at idx.
+func (s *scanner[V]) runeBefore(idx int) rune {
+	for i := 1; i <= len(s.runes) && i <= s.nrune; i++ {
+		if t := s.runes[(s.nrune-i)%len(s.runes)]; t.end == idx {
+			return t.r
+		}
+	}
+	return NoRune
+}
+
+// runeAfter returns a rune in the text which starts at idx.  It returns
+// false when the rune is not read yet.
+func (s *scanner[V]) runeAfter(idx int) (rune, bool) {
+	for i := 1; i <= len(s.runes) && i <= s.nrune; i++ {
+		if t := s.runes[(s.nrune-i)%len(s.runes)]; t.start == idx {
+			return t.r, true
+		}
+	}
+	if s.eof {
+		return NoRune, true
+	}
+	return 0, false
+}
+
+// finish reports all matches which are kept in the scanner.
+func (s *scanner[V]) finish(proc func(MatchOf[V]) bool) bool {
+	s.eof = true
+	if s.bounded && !s.resolve(NoRune, proc) {
+		return false
+	}
+	if s.folder != nil && !s.folder.flush(func(r rune, start, end int) bool {
+		return s.advance(r, start, end, proc)
+	}) {
+		return false
+	}
+	s.globWaits = s.globWaits[:0]
+	return s.flush(int(^uint(0)>>1), proc)
+}
+
+// flush reports candidates which start before limit, in leftmost order.
+func (s *scanner[V]) flush(limit int, proc func(MatchOf[V]) bool) bool {
+	for _, w := range s.waiting {
+		if w.Index < limit {
+			limit = w.Index
+		}
+	}
+	for _, w := range s.globWaits {
+		limit = min(limit, s.starts[w.first%len(s.starts)])
+	}
+	for len(s.pending) > 0 {
+		best := 0
+		for i := 1; i < len(s.pending); i++ {
+			if s.better(s.pending[i], s.pending[best]) {
+				best = i
+			}
+		}
+		b := s.pending[best]
+		if b.Index >= limit {
+			return true
+		}
+		s.end = b.End
+		n := 0
+		for _, c := range s.pending {
+			if c.Index >= s.end {
+				s.pending[n] = c
+				n++
+			}
+		}
+		s.pending = s.pending[:n]
+		if !s.report(b, proc) {
+			return false
+		}
+	}
+	return true
+}
+
+// pos returns an index which no matches reported later start before.
+func (s *scanner[V]) pos() int {
+	p := s.start
+	for _, c := range s.pending {
+		if c.Index < p {
+			p = c.Index
+		}
+	}
+	for _, w := range s.waiting {
+		if w.Index < p {
+			p = w.Index
+		}
+	}
+	for _, w := range s.globWaits {
+		p = min(p, s.starts[w.first%len(s.starts)])
+	}
+	return p
+}
+
+func (s *scanner[V]) better(a, b candidate[V]) bool {
+	if a.Index != b.Index {
+		return a.Index < b.Index
+	}
+	if s.mode == LeftmostFirst {
+		return a.id < b.id
+	}
+	return a.End > b.End
+}
@@ -0,0 +1,209 @@
+package ahocorasick
+
+import (
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Segment is a SegmentOf which has a value of any types.
+type Segment = SegmentOf[any]
+
+// SegmentOf is a part of text which is split by Segment or SegmentLongest.
+// A known segment is a match of a pattern, and an unknown segment is a run
+// of runes in same Unicode script which don't match any patterns.
+type SegmentOf[V any] struct {
+	Index int
+	End   int
+	Text  string
+	Known bool
+
+	// Pattern and Value are valid for known segments.
+	Pattern string
+	Value   V
+
+	// Script is the name of Unicode script of an unknown segment, like
+	// "Han" or "Latin".
+	Script string
+}
+
+// Segment splits text into segments which have the lowest total cost.  It
+// builds a lattice of all matches and unknown segments, then finds the best
+// path with Viterbi algorithm.  cost returns the cost of a segment, when it
+// is nil, a known segment costs 1 and an unknown segment costs 1 + its
+// length in runes.
+func (m *MatcherOf[V]) Segment(text string, cost func(SegmentOf[V]) int) ([]SegmentOf[V], error) {
+	if cost == nil {
+		cost = defaultCost[V]
+	}
+	l, err := m.lattice(text)
+	if err != nil {
+		return nil, err
+	}
+	type node struct {
+		cost    int
+		reached bool
+		prev    SegmentOf[V]
+	}
+	nodes := make([]node, len(text)+1)
+	nodes[0].reached = true
+	relax := func(from int, seg SegmentOf[V]) {
+		c := nodes[from].cost + cost(seg)
+		if n := &nodes[seg.End]; !n.reached || c < n.cost {
+			*n = node{cost: c, reached: true, prev: seg}
+		}
+	}
+	for i := 0; i < len(text); {
+		_, n := decodeString(l.bytes, text[i:])
+		if nodes[i].reached {
+			for _, v := range l.edges[i] {
+				relax(i, knownSegment(text, v))
+			}
+			relax(i, l.unknown(i))
+		}
+		i += n
+	}
+	var segs []SegmentOf[V]
+	for i := len(text); i > 0; i = nodes[i].prev.Index {
+		segs = append(segs, nodes[i].prev)
+	}
+	for i, j := 0, len(segs)-1; i < j; i, j = i+1, j-1 {
+		segs[i], segs[j] = segs[j], segs[i]
+	}
+	return segs, nil
+}
+
+// SegmentLongest splits text into segments greedily, from left to right it
+// takes the longest match or an unknown segment.
+func (m *MatcherOf[V]) SegmentLongest(text string) ([]SegmentOf[V], error) {
+	l, err := m.lattice(text)
+	if err != nil {
+		return nil, err
+	}
+	var segs []SegmentOf[V]
+	for i := 0; i < len(text); {
+		seg := l.unknown(i)
+		for j, v := range l.edges[i] {
+			if j == 0 || v.End > seg.End {
+				seg = knownSegment(text, v)
+			}
+		}
+		segs = append(segs, seg)
+		i = seg.End
+	}
+	return segs, nil
+}
+
+func defaultCost[V any](seg SegmentOf[V]) int {
+	if seg.Known {
+		return 1
+	}
+	return 1 + utf8.RuneCountInString(seg.Text)
+}
+
+func knownSegment[V any](text string, v MatchOf[V]) SegmentOf[V] {
+	return SegmentOf[V]{
+		Index:   v.Index,
+		End:     v.End,
+		Text:    text[v.Index:v.End],
+		Known:   true,
+		Pattern: v.Pattern,
+		Value:   v.Value,
+	}
+}
+
+// lattice keeps all matches in text by their start.
+type lattice[V any] struct {
+	text  string
+	bytes bool
+	edges [][]MatchOf[V]
+	// next is the next start of matches after each index.
+	next []int
+}
+
+func (m *MatcherOf[V]) lattice(text string) (*lattice[V], error) {
+	s, err := m.newScanner()
+	if err != nil {
+		return nil, err
+	}
+	s.mode = Standard
+	l := &lattice[V]{
+		text:  text,
+		bytes: s.bytes,
+		edges: make([][]MatchOf[V], len(text)+1),
+		next:  make([]int, len(text)+1),
+	}
+	scanString(s, text, func(v MatchOf[V]) bool {
+		if v.End > v.Index {
+			l.edges[v.Index] = append(l.edges[v.Index], v)
+		}
+		return true
+	})
+	next := len(text)
+	for i := len(text); i >= 0; i-- {
+		l.next[i] = next
+		if len(l.edges[i]) > 0 {
+			next = i
+		}
+	}
+	return l, nil
+}
+
+// unknown returns an unknown segment which starts at i.  It is a run of
+// runes in same script, and ends before the next match.
+func (l *lattice[V]) unknown(i int) SegmentOf[V] {
+	r, n := decodeString(l.bytes, l.text[i:])
+	script := scriptOf(r)
+	end := i + n
+	for end < l.next[i] {
+		r, n := decodeString(l.bytes, l.text[end:])
+		if sc := scriptOf(r); sc != script && !joinsScript(r, sc, script) {
+			break
+		}
+		end += n
+	}
+	return SegmentOf[V]{
+		Index:  i,
+		End:    end,
+		Text:   l.text[i:end],
+		Script: script,
+	}
+}
+
+var (
+	scriptOnce  sync.Once
+	scriptNames []string
+)
+
+// scriptOf returns the name of Unicode script of r, or "" when r doesn't
+// belong to any scripts.
+func scriptOf(r rune) string {
+	switch {
+	case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		return "Latin"
+	case r < utf8.RuneSelf:
+		return "Common"
+	}
+	scriptOnce.Do(func() {
+		for name := range unicode.Scripts {
+			scriptNames = append(scriptNames, name)
+		}
+		sort.Strings(scriptNames)
+	})
+	for _, name := range scriptNames {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	return ""
+}
+
+// joinsScript returns true when r in script sc continues a run of script.
+// Combining marks and prolonged sound marks of kana continue any runs.
+func joinsScript(r rune, sc, script string) bool {
+	if sc == "Inherited" {
+		return true
+	}
+	return r == 'ー' && (script == "Katakana" || script == "Hiragana")
+}
@@ -0,0 +1,91 @@
+package ahocorasick
+
+import (
+	"testing"
+)
+
+func assertSegments[V comparable](t *testing.T, exp []string, act []SegmentOf[V], err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal("segmentation failed:", err)
+	}
+	var texts []string
+	for _, s := range act {
+		texts = append(texts, s.Text)
+	}
+	if len(texts) != len(exp) {
+		t.Fatalf("segments not matched:\n  expected: %q\n  actually: %q", exp, texts)
+	}
+	for i := range exp {
+		if texts[i] != exp[i] {
+			t.Fatalf("segments not matched:\n  expected: %q\n  actually: %q", exp, texts)
+		}
+	}
+}
+
+func TestSegment(t *testing.T) {
+	m := New()
+	m.Add("ab", 1)
+	m.Add("abc", 2)
+	m.Add("cd", 3)
+	m.Compile()
+	segs, err := m.Segment("abcd", nil)
+	assertSegments(t, []string{"ab", "cd"}, segs, err)
+	segs, err = m.SegmentLongest("abcd")
+	assertSegments(t, []string{"abc", "d"}, segs, err)
+	if !segs[0].Known || segs[0].Pattern != "abc" || segs[0].Value != 2 {
+		t.Errorf("unexpected known segment: %+v", segs[0])
+	}
+	if segs[1].Known || segs[1].Script != "Latin" || segs[1].Index != 3 || segs[1].End != 4 {
+		t.Errorf("unexpected unknown segment: %+v", segs[1])
+	}
+	segs, err = m.Segment("", nil)
+	assertSegments(t, nil, segs, err)
+}
+
+func TestSegmentCost(t *testing.T) {
+	m := NewOf[int]()
+	m.Add("ab", 5)
+	m.Add("a", 1)
+	m.Add("b", 1)
+	m.Compile(WithMode(LeftmostLongest))
+	segs, err := m.Segment("abc", nil)
+	assertSegments(t, []string{"ab", "c"}, segs, err)
+	segs, err = m.Segment("abc", func(s SegmentOf[int]) int {
+		if s.Known {
+			return s.Value
+		}
+		return 100 * len(s.Text)
+	})
+	assertSegments(t, []string{"a", "b", "c"}, segs, err)
+}
+
+func TestSegmentScripts(t *testing.T) {
+	m := New()
+	m.Add("東京", 1)
+	m.Add("ガイド", 2)
+	m.Compile(WithFold(FoldWidth))
+	text := "東京とTokyo123ラーメンｶﾞｲﾄﾞé"
+	exp := []string{"東京", "と", "Tokyo", "123", "ラーメン", "ｶﾞｲﾄﾞ", "é"}
+	segs, err := m.Segment(text, nil)
+	assertSegments(t, exp, segs, err)
+	segs, err = m.SegmentLongest(text)
+	assertSegments(t, exp, segs, err)
+	scripts := []string{"", "Hiragana", "Latin", "Common", "Katakana", "", "Latin"}
+	for i, s := range segs {
+		if s.Script != scripts[i] {
+			t.Errorf("script of %q is not %q: %q", s.Text, scripts[i], s.Script)
+		}
+	}
+}
+
+func TestSegmentNotCompiled(t *testing.T) {
+	m := New()
+	m.Add("a", 1)
+	if _, err := m.Segment("a", nil); err != ErrorNotCompiled {
+		t.Errorf("Segment should fail with ErrorNotCompiled: %v", err)
+	}
+	if _, err := m.SegmentLongest("a"); err != ErrorNotCompiled {
+		t.Errorf("SegmentLongest should fail with ErrorNotCompiled: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package ahocorasick
+
+import (
+	"iter"
+
+	trie0 "github.com/koron/gelatin/trie0"
+)
+
+// SeqMatcher finds patterns which are sequences of keys (ex. tokens) in a
+// sequence of keys with Aho-Corasick algorithm.  All keys in patterns and
+// input should be same type.
+type SeqMatcher[V any] struct {
+	trie     *trie0.TernaryTrie
+	compiled bool
+}
+
+// SeqMatch represents a match in a sequence of keys.  Start and End are
+// indexes of keys, the match is keys[Start:End].
+type SeqMatch[V any] struct {
+	Start   int
+	End     int
+	Pattern trie0.KeySeq
+	Value   V
+}
+
+type seqNodeData[V any] struct {
+	pattern trie0.KeySeq
+	value   V
+	failure *trie0.TernaryNode
+	depth   int
+}
+
+// NewSeqMatcher creates a SeqMatcher.
+func NewSeqMatcher[V any]() *SeqMatcher[V] {
+	return &SeqMatcher[V]{
+		trie: trie0.NewTernaryTrie(),
+	}
+}
+
+// Add adds a pattern with a value.  Compile is required after Add.
+func (m *SeqMatcher[V]) Add(pattern trie0.KeySeq, v V) {
+	m.trie.Put(pattern, &seqNodeData[V]{pattern: pattern, value: v})
+	m.compiled = false
+}
+
+// Compile fills failures of all nodes.
+func (m *SeqMatcher[V]) Compile() error {
+	m.trie.Balance()
+	root := m.trie.Root().(*trie0.TernaryNode)
+	root.SetValue(&seqNodeData[V]{failure: root})
+	trie0.EachWidth(m.trie, func(n trie0.Node) bool {
+		parent := n.(*trie0.TernaryNode)
+		pdata := getSeqNodeData[V](parent)
+		parent.Each(func(c trie0.Node) bool {
+			curr := c.(*trie0.TernaryNode)
+			data := getSeqNodeData[V](curr)
+			if data == nil {
+				data = &seqNodeData[V]{}
+				curr.SetValue(data)
+			}
+			data.depth = pdata.depth + 1
+			if parent == root {
+				data.failure = root
+			} else {
+				data.failure = m.next(pdata.failure, curr.Label())
+			}
+			return true
+		})
+		return true
+	})
+	m.compiled = true
+	return nil
+}
+
+func (m *SeqMatcher[V]) next(node *trie0.TernaryNode, k trie0.Key) *trie0.TernaryNode {
+	root := m.trie.Root().(*trie0.TernaryNode)
+	for {
+		next, _ := node.Get(k).(*trie0.TernaryNode)
+		if next != nil {
+			return next
+		} else if node == root {
+			return root
+		}
+		node = getSeqNodeData[V](node).failure
+	}
+}
+
+// Each calls proc for each match in seq, including overlapping ones.  It
+// stops scanning when proc returns false.
+func (m *SeqMatcher[V]) Each(seq trie0.KeySeq, proc func(SeqMatch[V]) bool) error {
+	if !m.compiled {
+		return ErrorNotCompiled
+	}
+	root := m.trie.Root().(*trie0.TernaryNode)
+	curr := root
+	for i, k := range seq.Keys() {
+		curr = m.next(curr, k)
+		for n := curr; n != root; {
+			d := getSeqNodeData[V](n)
+			if d.pattern != nil && !proc(SeqMatch[V]{
+				Start:   i + 1 - d.depth,
+				End:     i + 1,
+				Pattern: d.pattern,
+				Value:   d.value,
+			}) {
+				return nil
+			}
+			n = d.failure
+		}
+	}
+	return nil
+}
+
+// All returns an iterator over matches in seq.
+func (m *SeqMatcher[V]) All(seq trie0.KeySeq) iter.Seq[SeqMatch[V]] {
+	return func(yield func(SeqMatch[V]) bool) {
+		m.Each(seq, yield)
+	}
+}
+
+func getSeqNodeData[V any](node *trie0.TernaryNode) *seqNodeData[V] {
+	d, _ := node.Value().(*seqNodeData[V])
+	return d
+}
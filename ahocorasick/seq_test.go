@@ -0,0 +1,61 @@
+package ahocorasick
+
+import (
+	"testing"
+
+	trie0 "github.com/koron/gelatin/trie0"
+)
+
+func TestSeqMatcher(t *testing.T) {
+	m := NewSeqMatcher[string]()
+	m.Add(trie0.KeySeqStrings{"new", "york"}, "city")
+	m.Add(trie0.KeySeqStrings{"york"}, "town")
+	m.Add(trie0.KeySeqStrings{"new", "york", "times"}, "paper")
+	m.Add(trie0.KeySeqStrings{"times", "square"}, "place")
+	text := trie0.KeySeqStrings{"the", "new", "york", "times", "square", "in", "new", "york"}
+	if err := m.Each(text, func(SeqMatch[string]) bool { return true }); err != ErrorNotCompiled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	m.Compile()
+	type result struct {
+		start, end int
+		value      string
+	}
+	exp := []result{
+		{1, 3, "city"},
+		{2, 3, "town"},
+		{1, 4, "paper"},
+		{3, 5, "place"},
+		{6, 8, "city"},
+		{7, 8, "town"},
+	}
+	var act []result
+	for v := range m.All(text) {
+		act = append(act, result{v.Start, v.End, v.Value})
+	}
+	if len(act) != len(exp) {
+		t.Fatalf("unexpected matches: %v", act)
+	}
+	for i, e := range exp {
+		if act[i] != e {
+			t.Errorf("unexpected match at #%d: %v, expected %v", i, act[i], e)
+		}
+	}
+}
+
+func TestSeqMatcherInts(t *testing.T) {
+	m := NewSeqMatcher[int]()
+	m.Add(trie0.KeySeqInts{1, 2}, 12)
+	m.Add(trie0.KeySeqInts{2, 3, 4}, 234)
+	m.Add(trie0.KeySeqInts{2, 3, 5}, 235)
+	m.Compile()
+	var values []int
+	m.Each(trie0.KeySeqInts{1, 2, 3, 5, 1, 2, 3, 4}, func(v SeqMatch[int]) bool {
+		values = append(values, v.Value)
+		return true
+	})
+	if len(values) != 4 || values[0] != 12 || values[1] != 235 ||
+		values[2] != 12 || values[3] != 234 {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
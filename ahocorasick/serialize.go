@@ -0,0 +1,430 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/koron/gelatin/trie"
+)
+
+// Serialized matcher consists of:
+//
+//	magic (4 bytes) + version (1 byte) + length of payload (8 bytes)
+//	+ payload + CRC32 (IEEE) of payload (4 bytes)
+//
+// The payload contains config, patterns, values encoded by ValueCodec and
+// states of the automaton in width order.  Each state except the root has
+// its parent, label, patterns, wildcard patterns anchored at it and failure,
+// so loading doesn't need to compute failures again.
+const (
+	serialMagic   = "GACM"
+	serialVersion = 5
+)
+
+// ValueCodec encodes and decodes values of patterns for serialization.
+type ValueCodec interface {
+	EncodeValues(values []interface{}) ([]byte, error)
+	DecodeValues(data []byte) ([]interface{}, error)
+}
+
+// GobCodec is a ValueCodec with encoding/gob.  Concrete types of values
+// except basic ones should be registered with gob.Register.
+type GobCodec struct{}
+
+type gobValue struct {
+	V interface{}
+}
+
+// EncodeValues implements ValueCodec.
+func (GobCodec) EncodeValues(values []interface{}) ([]byte, error) {
+	vv := make([]gobValue, len(values))
+	for i, v := range values {
+		vv[i].V = v
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(vv); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// DecodeValues implements ValueCodec.
+func (GobCodec) DecodeValues(data []byte) ([]interface{}, error) {
+	var vv []gobValue
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&vv); err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(vv))
+	for i, v := range vv {
+		values[i] = v.V
+	}
+	return values, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler with GobCodec.
+func (m *MatcherOf[V]) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := m.Encode(&b, GobCodec{}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler with GobCodec.
+func (m *MatcherOf[V]) UnmarshalBinary(data []byte) error {
+	_, err := m.Decode(bytes.NewReader(data), GobCodec{})
+	return err
+}
+
+// WriteTo implements io.WriterTo with GobCodec.
+func (m *MatcherOf[V]) WriteTo(w io.Writer) (int64, error) {
+	return m.Encode(w, GobCodec{})
+}
+
+// ReadFrom implements io.ReaderFrom with GobCodec.
+func (m *MatcherOf[V]) ReadFrom(r io.Reader) (int64, error) {
+	return m.Decode(r, GobCodec{})
+}
+
+// Encode writes the compiled matcher to w, values are encoded with c.
+func (m *MatcherOf[V]) Encode(w io.Writer, c ValueCodec) (int64, error) {
+	if err := m.prepare(); err != nil {
+		return 0, err
+	}
+	if m.conf.boundary != nil {
+		return 0, ErrorUnserializable
+	}
+	for _, e := range m.entries {
+		if e.boundary != nil {
+			return 0, ErrorUnserializable
+		}
+	}
+	payload, err := m.encodePayload(c)
+	if err != nil {
+		return 0, err
+	}
+	b := make([]byte, 0, len(payload)+17)
+	b = append(b, serialMagic...)
+	b = append(b, serialVersion)
+	b = binary.BigEndian.AppendUint64(b, uint64(len(payload)))
+	b = append(b, payload...)
+	b = binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(payload))
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (m *MatcherOf[V]) encodePayload(c ValueCodec) ([]byte, error) {
+	var b []byte
+	b = binary.AppendUvarint(b, uint64(m.conf.mode))
+	b = binary.AppendUvarint(b, uint64(m.conf.fold))
+	var flags byte
+	if m.conf.dfa {
+		flags |= 1
+	}
+	if m.conf.bytes {
+		flags |= 2
+	}
+	b = append(b, flags)
+	b = binary.AppendUvarint(b, uint64(m.conf.track))
+	b = binary.AppendUvarint(b, uint64(m.dup))
+	values := make([]interface{}, len(m.entries))
+	b = binary.AppendUvarint(b, uint64(len(m.entries)))
+	for i, e := range m.entries {
+		b = appendString(b, e.pattern)
+		b = binary.AppendUvarint(b, uint64(e.groups))
+		var flags byte
+		if e.wildcard {
+			flags |= 1
+		}
+		b = append(b, flags)
+		values[i] = e.value
+	}
+	vb, err := c.EncodeValues(values)
+	if err != nil {
+		return nil, err
+	}
+	b = appendString(b, string(vb))
+
+	root := m.trie.Root().(*trie.TernaryNode)
+	ids := map[*trie.TernaryNode]int{root: 0}
+	var states []byte
+	trie.EachWidth(m.trie, func(n trie.Node) bool {
+		parent := n.(*trie.TernaryNode)
+		parent.Each(func(child trie.Node) bool {
+			tn := child.(*trie.TernaryNode)
+			ids[tn] = len(ids)
+			d := getNodeData[V](tn)
+			states = binary.AppendUvarint(states, uint64(ids[parent]))
+			states = binary.AppendUvarint(states, uint64(uint32(tn.Label())))
+			if d.pattern == nil {
+				states = append(states, 0)
+			} else {
+				states = binary.AppendUvarint(states, uint64(len(d.dups)+1))
+				states = binary.AppendUvarint(states, uint64(d.id))
+				for _, dup := range d.dups {
+					states = binary.AppendUvarint(states, uint64(dup.id))
+				}
+			}
+			states = binary.AppendUvarint(states, uint64(len(d.globs)))
+			for _, g := range d.globs {
+				states = binary.AppendUvarint(states, uint64(g.id))
+			}
+			states = binary.AppendUvarint(states, uint64(ids[d.failure]))
+			return true
+		})
+		return true
+	})
+	b = binary.AppendUvarint(b, uint64(len(ids)))
+	return append(b, states...), nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// Decode restores a compiled matcher from r, values are decoded with c.
+// Patterns and values of m are replaced.
+func (m *MatcherOf[V]) Decode(r io.Reader, c ValueCodec) (int64, error) {
+	head := make([]byte, 13)
+	n, err := io.ReadFull(r, head)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			err = ErrorInvalidFormat
+		}
+		return int64(n), err
+	}
+	if string(head[:4]) != serialMagic {
+		return int64(n), ErrorInvalidFormat
+	}
+	if head[4] != serialVersion {
+		return int64(n), ErrorUnsupportedVersion
+	}
+	size := binary.BigEndian.Uint64(head[5:])
+	if size > math.MaxInt64-4 {
+		return int64(n), ErrorInvalidFormat
+	}
+	var b bytes.Buffer
+	m2, err := io.CopyN(&b, r, int64(size)+4)
+	n += int(m2)
+	if err != nil {
+		if err == io.EOF {
+			err = ErrorInvalidFormat
+		}
+		return int64(n), err
+	}
+	data := b.Bytes()
+	if uint64(len(data)) != size+4 {
+		return int64(n), ErrorInvalidFormat
+	}
+	payload, sum := data[:size], data[size:]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(sum) {
+		return int64(n), ErrorChecksum
+	}
+	return int64(n), m.decodePayload(payload, c)
+}
+
+func (m *MatcherOf[V]) decodePayload(b []byte, c ValueCodec) error {
+	d := &decoder{b: b}
+	var conf config
+	conf.mode = MatchMode(d.uint())
+	conf.fold = Fold(d.uint())
+	flags := d.byte()
+	conf.dfa = flags&1 != 0
+	conf.bytes = flags&2 != 0
+	conf.track = Tracking(d.uint())
+	dup := DuplicatePolicy(d.uint())
+	entries := make([]entry[V], d.count())
+	for i := range entries {
+		entries[i].pattern = d.string()
+		entries[i].groups = GroupSet(d.uint())
+		entries[i].wildcard = d.byte()&1 != 0
+	}
+	vb := d.string()
+	if d.err != nil {
+		return d.err
+	}
+	values, err := c.DecodeValues([]byte(vb))
+	if err != nil {
+		return err
+	}
+	if len(values) != len(entries) {
+		return ErrorInvalidFormat
+	}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		x, ok := v.(V)
+		if !ok {
+			return ErrorValueType
+		}
+		entries[i].value = x
+	}
+
+	t := trie.NewTernaryTrie()
+	root := t.Root().(*trie.TernaryNode)
+	root.SetValue(&nodeData[V]{failure: root})
+	nodes := []*trie.TernaryNode{root}
+	failures := []int{0}
+	maxDepth, lookback := 0, 0
+	// lastParent is the parent of the previous state, parents of states in
+	// width order never decrease.
+	lastParent := 0
+	for i, num := 1, d.int(); d.err == nil && i < num; i++ {
+		parent, label := d.int(), rune(d.uint())
+		if parent >= len(nodes) || parent < lastParent {
+			return ErrorInvalidFormat
+		}
+		lastParent = parent
+		depth := getNodeData[V](nodes[parent]).depth + 1
+		data := &nodeData[V]{depth: depth}
+		for j, count := 0, d.count(); d.err == nil && j < count; j++ {
+			id := d.int()
+			if id >= len(entries) {
+				return ErrorInvalidFormat
+			}
+			e := &entries[id]
+			x := &nodeData[V]{pattern: &e.pattern, value: e.value, id: id, groups: e.groups, depth: depth}
+			if j == 0 {
+				data = x
+			} else {
+				data.dups = append(data.dups, x)
+			}
+		}
+		for j, count := 0, d.count(); d.err == nil && j < count; j++ {
+			id := d.int()
+			if id >= len(entries) || !entries[id].wildcard {
+				return ErrorInvalidFormat
+			}
+			e := &entries[id]
+			g, err := parseGlob(e.pattern, &conf)
+			if err != nil {
+				return ErrorInvalidFormat
+			}
+			data.globs = append(data.globs, &nodeData[V]{pattern: &e.pattern, value: e.value, id: id, groups: e.groups, depth: depth, glob: g})
+			maxDepth = max(maxDepth, g.maxLen)
+			lookback = max(lookback, g.lookback())
+		}
+		// states are in width order, so failures precede the state.
+		failure := d.int()
+		if failure >= len(nodes) {
+			return ErrorInvalidFormat
+		}
+		n, isnew := nodes[parent].Dig(label)
+		if !isnew {
+			return ErrorInvalidFormat
+		}
+		tn := n.(*trie.TernaryNode)
+		if data.depth > maxDepth {
+			maxDepth = data.depth
+		}
+		tn.SetValue(data)
+		nodes = append(nodes, tn)
+		failures = append(failures, failure)
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) != 0 {
+		return ErrorInvalidFormat
+	}
+	for i, n := range nodes[1:] {
+		getNodeData[V](n).failure = nodes[failures[i+1]]
+	}
+	t.Balance()
+
+	m.trie = t
+	m.conf = conf
+	m.entries = entries
+	m.reindex()
+	m.dup = dup
+	m.maxDepth = maxDepth
+	m.lookback = lookback
+	m.globbed = hasGlobs(entries)
+	m.stats = Stats{Patterns: len(entries), States: len(nodes), MaxDepth: maxDepth}
+	m.bounded = false
+	m.dfa = nil
+	if conf.dfa {
+		m.dfa = newDFA[V](t)
+		m.stats.TableBytes = m.dfa.tableBytes()
+	}
+	m.compiled = true
+	m.dirty.Store(false)
+	return nil
+}
+
+func hasGlobs[V any](entries []entry[V]) bool {
+	for _, e := range entries {
+		if e.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// decoder reads values from serialized payload.  It keeps the first error.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = ErrorInvalidFormat
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) int() int {
+	v := d.uint()
+	if v > math.MaxInt32 {
+		d.err = ErrorInvalidFormat
+		return 0
+	}
+	return int(v)
+}
+
+// count reads a number of following items, each of them has one byte at
+// least.
+func (d *decoder) count() int {
+	v := d.uint()
+	if v > uint64(len(d.b)) {
+		d.err = ErrorInvalidFormat
+		return 0
+	}
+	return int(v)
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.b) == 0 {
+		d.err = ErrorInvalidFormat
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *decoder) string() string {
+	l := d.count()
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.b[:l])
+	d.b = d.b[l:]
+	return s
+}
@@ -0,0 +1,143 @@
+package ahocorasick
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	m := newTestMatcher()
+	m.Add("ガイド", "guide")
+	m.Add("none", nil)
+	m.Compile(WithMode(LeftmostLongest), WithFold(FoldWidth|FoldCase))
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal("MarshalBinary failed:", err)
+	}
+	m2 := New()
+	if err := m2.UnmarshalBinary(data); err != nil {
+		t.Fatal("UnmarshalBinary failed:", err)
+	}
+	if m2.conf.mode != m.conf.mode || m2.conf.fold != m.conf.fold || m2.conf.dfa != m.conf.dfa {
+		t.Errorf("config unmatched: %+v != %+v", m2.conf, m.conf)
+	}
+	if m2.maxDepth != m.maxDepth {
+		t.Errorf("maxDepth unmatched: %d != %d", m2.maxDepth, m.maxDepth)
+	}
+	text := "xabcdex bab ｶﾞｲﾄﾞ NONE"
+	exp := matchAll(t, m, text)
+	if len(exp) != 4 {
+		t.Fatalf("unexpected matches: %+v", exp)
+	}
+	assertMatches(t, exp, matchAll(t, m2, text))
+
+	// restored matcher can be compiled again.
+	m2.Compile()
+	m.Compile()
+	assertMatches(t, matchAll(t, m, text), matchAll(t, m2, text))
+}
+
+func TestWriteTo(t *testing.T) {
+	m := newTestMatcher()
+	m.Compile(WithDFA())
+	var b bytes.Buffer
+	n, err := m.WriteTo(&b)
+	if err != nil {
+		t.Fatal("WriteTo failed:", err)
+	}
+	size := int64(b.Len())
+	if n != size {
+		t.Errorf("WriteTo returns %d, but wrote %d bytes", n, size)
+	}
+	b.WriteString("trailing")
+	m2 := New()
+	n, err = m2.ReadFrom(&b)
+	if err != nil {
+		t.Fatal("ReadFrom failed:", err)
+	}
+	if n != size {
+		t.Errorf("ReadFrom returns %d, expected %d", n, size)
+	}
+	if m2.dfa == nil {
+		t.Error("DFA isn't restored")
+	}
+	checkTree(t, m2)
+	assertMatches(t, matchAll(t, m, "abcdebab"), matchAll(t, m2, "abcdebab"))
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	m := newTestMatcher()
+	data, _ := m.MarshalBinary()
+	for _, c := range []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, ErrorInvalidFormat},
+		{"magic", append([]byte("XXXX"), data[4:]...), ErrorInvalidFormat},
+		{"version", append(append([]byte(nil), data[:4]...), append([]byte{99}, data[5:]...)...), ErrorUnsupportedVersion},
+		{"short", data[:len(data)-1], ErrorInvalidFormat},
+		{"checksum", append(append([]byte(nil), data[:len(data)-1]...), data[len(data)-1]^1), ErrorChecksum},
+	} {
+		if err := New().UnmarshalBinary(c.data); err != c.err {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+	}
+	if _, err := New().MarshalBinary(); err != ErrorNotCompiled {
+		t.Errorf("unexpected error for not compiled: %v", err)
+	}
+
+	// failures which point at the state itself or later states.
+	m = New()
+	m.Add("a", nil)
+	m.Add("b", nil)
+	m.Compile()
+	data, _ = m.MarshalBinary()
+	for _, failure := range []byte{1, 2} {
+		if err := New().UnmarshalBinary(resealStates(data, 2, func(states []byte) {
+			states[5] = failure
+		})); err != ErrorInvalidFormat {
+			t.Errorf("failure %d: unexpected error: %v", failure, err)
+		}
+	}
+	// a duplicated state.
+	if err := New().UnmarshalBinary(resealStates(data, 2, func(states []byte) {
+		states[7] = 'a'
+	})); err != ErrorInvalidFormat {
+		t.Errorf("duplicated state: unexpected error: %v", err)
+	}
+
+	// a state which is not in width order: "d" after "ab".
+	m = New()
+	m.Add("ab", nil)
+	m.Add("cd", nil)
+	m.Compile()
+	data, _ = m.MarshalBinary()
+	if err := New().UnmarshalBinary(resealStates(data, 4, func(states []byte) {
+		states[18] = 0
+	})); err != ErrorInvalidFormat {
+		t.Errorf("width order: unexpected error: %v", err)
+	}
+
+	// an oversized length of the payload.
+	data = append([]byte(nil), data...)
+	binary.BigEndian.PutUint64(data[5:], math.MaxUint64)
+	if err := New().UnmarshalBinary(data); err != ErrorInvalidFormat {
+		t.Errorf("oversized length: unexpected error: %v", err)
+	}
+}
+
+// resealStates rewrites last n states of serialized data with edit, and
+// updates the checksum.  The matcher has single-byte patterns without
+// duplicates and wildcard patterns, so each state has 6 bytes: parent,
+// label, a pattern, its id, no globs and failure.
+func resealStates(data []byte, n int, edit func(states []byte)) []byte {
+	data = append([]byte(nil), data...)
+	payload := data[13 : len(data)-4]
+	edit(payload[len(payload)-6*n:])
+	binary.BigEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(payload))
+	return data
+}
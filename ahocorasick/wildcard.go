@@ -0,0 +1,323 @@
+package ahocorasick
+
+import (
+	"strings"
+)
+
+// Wildcard returns a PatternOption to parse the pattern with wildcard
+// syntax.  Patterns without it are always literal.  The syntax is:
+//
+//	?        any rune
+//	[abc]    a rune in the set, ranges like [a-z] are allowed
+//	[^abc]   a rune not in the set
+//	X{n}     X repeated n times, X is ? or a set
+//	X{n,m}   X repeated from n to m times (m <= 64), the shortest is matched
+//	\c       a literal rune c, for ?, [, ], {, } and \
+//
+// Other runes are literal.  A pattern must have one or more literal runes,
+// the longest run of them is put into the automaton and others are checked
+// around its matches.  So a match of a wildcard pattern is reported after
+// runes which the pattern may need.  Runes in sets are folded like patterns
+// as long as ranges keep their order.
+func Wildcard() PatternOption {
+	return func(c *patternConfig) {
+		c.wildcard = true
+	}
+}
+
+// maxRepeat is the maximum count of repetition in wildcard patterns.
+const maxRepeat = 64
+
+// glob is a parsed wildcard pattern which is split at its anchor, the
+// longest run of literal runes.
+type glob struct {
+	anchor string
+	// prefix and suffix are elements before and after the anchor.
+	prefix, suffix []globElem
+	// maxLen is the maximum length of matches in runes.
+	maxLen int
+}
+
+type globElem struct {
+	any    bool
+	lit    rune
+	ranges []runeRange
+	negate bool
+	min    int
+	max    int
+}
+
+type runeRange struct {
+	lo, hi rune
+}
+
+func (e *globElem) isLiteral() bool {
+	return !e.any && e.ranges == nil
+}
+
+func (e *globElem) accepts(r rune) bool {
+	switch {
+	case e.any:
+		return true
+	case e.ranges == nil:
+		return r == e.lit
+	}
+	for _, x := range e.ranges {
+		if r >= x.lo && r <= x.hi {
+			return !e.negate
+		}
+	}
+	return e.negate
+}
+
+// parseGlob parses a wildcard pattern, and folds its runes with c.
+func parseGlob(pattern string, c *config) (*glob, error) {
+	var elems []globElem
+	rs := []rune(pattern)
+	if c.bytes {
+		rs = []rune(bytesKey(pattern))
+	}
+	// repeatable is true when the last element can be repeated.
+	repeatable := false
+	for i := 0; i < len(rs); i++ {
+		e := globElem{min: 1, max: 1}
+		switch rs[i] {
+		case '?':
+			e.any = true
+		case '[':
+			n, err := parseSet(rs[i+1:], &e, c)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+		case '{':
+			if !repeatable {
+				return nil, ErrorWildcardSyntax
+			}
+			n, err := parseRepeat(rs[i+1:], &elems[len(elems)-1])
+			if err != nil {
+				return nil, err
+			}
+			i += n
+			repeatable = false
+			continue
+		case '\\':
+			if i++; i >= len(rs) {
+				return nil, ErrorWildcardSyntax
+			}
+			e.lit = rs[i]
+		default:
+			e.lit = rs[i]
+		}
+		elems = append(elems, e)
+		repeatable = !e.isLiteral()
+	}
+
+	// find the longest run of literal runes.
+	start, end := 0, 0
+	for i := 0; i < len(elems); {
+		j := i
+		for j < len(elems) && elems[j].isLiteral() {
+			j++
+		}
+		if j-i > end-start {
+			start, end = i, j
+		}
+		i = j + 1
+	}
+	if start == end {
+		return nil, ErrorNoLiteral
+	}
+	var b strings.Builder
+	for _, e := range elems[start:end] {
+		b.WriteRune(e.lit)
+	}
+	g := &glob{
+		anchor: b.String(),
+		prefix: elems[:start],
+		suffix: elems[end:],
+	}
+	if !c.bytes {
+		g.anchor = foldString(c.fold, g.anchor)
+	}
+	for _, e := range elems {
+		g.maxLen += e.max
+	}
+	for _, es := range [][]globElem{g.prefix, g.suffix} {
+		for i := range es {
+			foldElem(&es[i], c)
+		}
+	}
+	return g, nil
+}
+
+// parseSet parses a set after '[', and returns the number of runes which
+// are parsed including ']'.
+func parseSet(rs []rune, e *globElem, c *config) (int, error) {
+	i := 0
+	if i < len(rs) && rs[i] == '^' {
+		e.negate = true
+		i++
+	}
+	e.ranges = []runeRange{}
+	for ; i < len(rs) && rs[i] != ']'; i++ {
+		lo := rs[i]
+		if lo == '\\' {
+			if i++; i >= len(rs) {
+				return 0, ErrorWildcardSyntax
+			}
+			lo = rs[i]
+		}
+		hi := lo
+		if i+2 < len(rs) && rs[i+1] == '-' && rs[i+2] != ']' {
+			i += 2
+			if hi = rs[i]; hi == '\\' {
+				if i++; i >= len(rs) {
+					return 0, ErrorWildcardSyntax
+				}
+				hi = rs[i]
+			}
+			if hi < lo {
+				return 0, ErrorWildcardSyntax
+			}
+		}
+		e.ranges = append(e.ranges, runeRange{lo, hi})
+	}
+	if i >= len(rs) || len(e.ranges) == 0 {
+		return 0, ErrorWildcardSyntax
+	}
+	return i + 1, nil
+}
+
+// parseRepeat parses a repetition after '{', and returns the number of
+// runes which are parsed including '}'.
+func parseRepeat(rs []rune, e *globElem) (int, error) {
+	nums := []int{0}
+	i := 0
+	for ; i < len(rs) && rs[i] != '}'; i++ {
+		switch r := rs[i]; {
+		case r >= '0' && r <= '9':
+			n := &nums[len(nums)-1]
+			if *n = *n*10 + int(r-'0'); *n > maxRepeat {
+				return 0, ErrorWildcardSyntax
+			}
+		case r == ',' && len(nums) == 1 && i > 0:
+			nums = append(nums, 0)
+		default:
+			return 0, ErrorWildcardSyntax
+		}
+	}
+	if i >= len(rs) || i == 0 || rs[i-1] == ',' {
+		return 0, ErrorWildcardSyntax
+	}
+	e.min, e.max = nums[0], nums[len(nums)-1]
+	if e.max < e.min || e.max == 0 {
+		return 0, ErrorWildcardSyntax
+	}
+	return i + 1, nil
+}
+
+// foldElem folds runes of e.  Folded ranges are added to original ones.
+func foldElem(e *globElem, c *config) {
+	if c.bytes || c.fold == 0 {
+		return
+	}
+	f := &folder{fold: c.fold}
+	if e.isLiteral() {
+		e.lit = []rune(foldString(c.fold, string(e.lit)))[0]
+		return
+	}
+	for _, x := range e.ranges {
+		lo, hi := f.foldRune(x.lo), f.foldRune(x.hi)
+		if hi-lo == x.hi-x.lo && (lo != x.lo || hi != x.hi) {
+			e.ranges = append(e.ranges, runeRange{lo, hi})
+		}
+	}
+}
+
+// lookback returns the maximum length of the prefix in runes.
+func (g *glob) lookback() int {
+	n := 0
+	for _, e := range g.prefix {
+		n += e.max
+	}
+	return n
+}
+
+// matchPrefix checks the prefix of g matches runes before the anchor which
+// starts at a folded rune first.  It returns the index of the first rune of
+// the match.
+func (g *glob) matchPrefix(first int, label func(int) rune) (int, bool) {
+	return matchBack(g.prefix, first, label)
+}
+
+// matchBack matches elems backward with runes before k, the shortest match
+// is preferred.
+func matchBack(elems []globElem, k int, label func(int) rune) (int, bool) {
+	if len(elems) == 0 {
+		return k, true
+	}
+	e := &elems[len(elems)-1]
+	for n := 0; n <= e.max; n++ {
+		if n > 0 {
+			if k-n < 0 || !e.accepts(label(k-n)) {
+				return 0, false
+			}
+		}
+		if n < e.min {
+			continue
+		}
+		if start, ok := matchBack(elems[:len(elems)-1], k-n, label); ok {
+			return start, true
+		}
+	}
+	return 0, false
+}
+
+// globState is a state of matching the suffix: i is an index of elements
+// and n is the count of repetition of it.
+type globState struct {
+	i, n int
+}
+
+// begin returns states to match the suffix.  It returns true when the
+// suffix can be empty.
+func (g *glob) begin() ([]globState, bool) {
+	return g.closure([]globState{{0, 0}})
+}
+
+// step moves states by a rune r.  It returns true when the suffix is
+// matched.
+func (g *glob) step(states []globState, r rune) ([]globState, bool) {
+	next := states[:0]
+	for _, st := range states {
+		if st.i < len(g.suffix) && st.n < g.suffix[st.i].max && g.suffix[st.i].accepts(r) {
+			next = append(next, globState{st.i, st.n + 1})
+		}
+	}
+	return g.closure(next)
+}
+
+// closure adds states which skip elements repeated enough.
+func (g *glob) closure(states []globState) ([]globState, bool) {
+	for i := 0; i < len(states); i++ {
+		st := states[i]
+		if st.i == len(g.suffix) {
+			return nil, true
+		}
+		if st.n >= g.suffix[st.i].min {
+			next := globState{st.i + 1, 0}
+			found := false
+			for _, x := range states {
+				if x == next {
+					found = true
+					break
+				}
+			}
+			if !found {
+				states = append(states, next)
+			}
+		}
+	}
+	return states, false
+}
@@ -0,0 +1,150 @@
+package ahocorasick
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newWildcardMatcher(t *testing.T, patterns []string, opts ...Option) *Matcher {
+	t.Helper()
+	m := New()
+	for i, p := range patterns {
+		m.Add(p, i, Wildcard())
+	}
+	if err := m.Compile(opts...); err != nil {
+		t.Fatal("Compile failed:", err)
+	}
+	return m
+}
+
+// assertSpans checks matched texts in addition to assertMatches.
+func assertSpans(t *testing.T, text string, exp []string, act []Match) {
+	t.Helper()
+	var spans []string
+	for _, v := range act {
+		spans = append(spans, text[v.Index:v.End])
+	}
+	if strings.Join(spans, "|") != strings.Join(exp, "|") {
+		t.Errorf("unexpected spans: %q (expected %q)", spans, exp)
+	}
+}
+
+func TestWildcard(t *testing.T) {
+	m := newWildcardMatcher(t, []string{"ID-????-X", "colo[u]{0,1}r"})
+	text := "ID-12AB-X colour color ID-123-X"
+	act := matchAll(t, m, text)
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "ID-????-X", Value: 0},
+		{Index: 10, Pattern: "colo[u]{0,1}r", Value: 1},
+		{Index: 17, Pattern: "colo[u]{0,1}r", Value: 1},
+	}, act)
+	assertSpans(t, text, []string{"ID-12AB-X", "colour", "color"}, act)
+}
+
+func TestWildcardSet(t *testing.T) {
+	m := newWildcardMatcher(t, []string{"[0-9]{2,4}kg", "x[^0-9]y", `a\?b`, `\[x\]`})
+	text := "weight 12345kg x1y xay a?b axb [x]"
+	act := matchAll(t, m, text)
+	assertMatches(t, []Match{
+		{Index: 10, Pattern: "[0-9]{2,4}kg", Value: 0},
+		{Index: 19, Pattern: "x[^0-9]y", Value: 1},
+		{Index: 23, Pattern: `a\?b`, Value: 2},
+		{Index: 31, Pattern: `\[x\]`, Value: 3},
+	}, act)
+	assertSpans(t, text, []string{"45kg", "xay", "a?b", "[x]"}, act)
+}
+
+func TestWildcardInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		err     error
+	}{
+		{"a[b", ErrorWildcardSyntax},
+		{"a[]", ErrorWildcardSyntax},
+		{"[z-a]b", ErrorWildcardSyntax},
+		{"a{2}", ErrorWildcardSyntax},
+		{"a?{2", ErrorWildcardSyntax},
+		{"a?{0}", ErrorWildcardSyntax},
+		{"a?{3,1}", ErrorWildcardSyntax},
+		{"a?{65}", ErrorWildcardSyntax},
+		{"a?{2}{3}", ErrorWildcardSyntax},
+		{`a\`, ErrorWildcardSyntax},
+		{"???", ErrorNoLiteral},
+		{"[ab]", ErrorNoLiteral},
+	} {
+		m := New()
+		m.Add(tc.pattern, nil, Wildcard())
+		err := m.Compile()
+		var perr *ErrorInvalidPattern
+		if !errors.As(err, &perr) || perr.Pattern != tc.pattern || !errors.Is(err, tc.err) {
+			t.Errorf("unexpected error for %q: %v", tc.pattern, err)
+		}
+	}
+
+	// patterns without Wildcard are literal.
+	m := New()
+	m.Add("a[b", nil)
+	if err := m.Compile(); err != nil {
+		t.Fatal("Compile failed:", err)
+	}
+	assertMatches(t, []Match{{Index: 1, Pattern: "a[b"}}, matchAll(t, m, "xa[b"))
+}
+
+func TestWildcardFold(t *testing.T) {
+	m := newWildcardMatcher(t, []string{"id-[a-z]{2}", "[A-Z]x"}, WithFold(FoldCase))
+	text := "ID-Ab QX"
+	act := matchAll(t, m, text)
+	assertMatches(t, []Match{
+		{Index: 0, Pattern: "id-[a-z]{2}", Value: 0},
+		{Index: 6, Pattern: "[A-Z]x", Value: 1},
+	}, act)
+	assertSpans(t, text, []string{"ID-Ab", "QX"}, act)
+}
+
+func TestWildcardLeftmost(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithMode(LeftmostLongest)},
+		{WithMode(LeftmostLongest), WithDFA()},
+	} {
+		m := New()
+		m.Add("abc", 0)
+		m.Add("bc", 1)
+		m.Add("a?cd", 2, Wildcard())
+		m.Compile(opts...)
+		assertMatches(t, []Match{
+			{Index: 0, Pattern: "a?cd", Value: 2},
+			{Index: 5, Pattern: "abc", Value: 0},
+		}, matchAll(t, m, "abcd abce"))
+	}
+}
+
+func TestWildcardReader(t *testing.T) {
+	m := newWildcardMatcher(t, []string{"ID-????-X", "[0-9]{2,4}kg"})
+	text := "ID-12AB-X 12345kg ID-XXXX-X"
+	exp := matchAll(t, m, text)
+	if len(exp) != 3 {
+		t.Fatalf("unexpected matches: %+v", exp)
+	}
+	assertMatches(t, exp, matchReaderAll(t, m, strings.NewReader(text)))
+}
+
+func TestWildcardSerialize(t *testing.T) {
+	m := newWildcardMatcher(t, []string{"ID-????-X", "[0-9]{2,4}kg"}, WithMode(LeftmostLongest))
+	m.Add("ID-", 2)
+	m.Compile(WithMode(LeftmostLongest))
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatal("MarshalBinary failed:", err)
+	}
+	m2 := New()
+	if err := m2.UnmarshalBinary(data); err != nil {
+		t.Fatal("UnmarshalBinary failed:", err)
+	}
+	text := "ID-12AB-X 12345kg ID-1"
+	exp := matchAll(t, m, text)
+	if len(exp) != 3 {
+		t.Fatalf("unexpected matches: %+v", exp)
+	}
+	assertMatches(t, exp, matchAll(t, m2, text))
+}
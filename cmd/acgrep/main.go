@@ -0,0 +1,286 @@
+// Command acgrep searches files for many fixed patterns at once with the
+// Aho-Corasick matcher.
+//
+// Usage:
+//
+//	acgrep [options] -f PATTERNS [FILE...]
+//
+// PATTERNS has a pattern per line, or a pattern and a value separated by a
+// tab per line with -tsv.  acgrep reads standard input when no FILEs are
+// given.  -c prints counts of matched patterns in sorted order.  The exit
+// status is 0 when any matches are found, 1 when no matches are found and 2
+// on errors.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/koron/gelatin/ahocorasick"
+)
+
+const (
+	colorMatch = "\x1b[01;31m"
+	colorFile  = "\x1b[35m"
+	colorLine  = "\x1b[32m"
+	colorReset = "\x1b[0m"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type grep struct {
+	m     *ahocorasick.MatcherOf[string]
+	out   *bufio.Writer
+	only  bool
+	color bool
+	// counts keeps numbers of matches for each pattern.
+	counts map[string]int
+	found  bool
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("acgrep", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		patFile    = fs.String("f", "", "read patterns from `file`")
+		tsv        = fs.Bool("tsv", false, "read patterns as TSV of pattern and value")
+		mode       = fs.String("mode", "longest", "match `mode`: standard, longest or first")
+		ignoreCase = fs.Bool("i", false, "ignore case distinctions")
+		word       = fs.Bool("w", false, "match only whole words")
+		count      = fs.Bool("c", false, "print counts of matches per pattern")
+		only       = fs.Bool("o", false, "print only matched parts")
+		color      = fs.String("color", "auto", "highlight matches `when`: auto, always or never")
+	)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: acgrep [options] -f PATTERNS [FILE...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *patFile == "" {
+		fs.Usage()
+		return 2
+	}
+	opts, err := matchOptions(*mode, *ignoreCase, *word)
+	if err != nil {
+		fmt.Fprintln(stderr, "acgrep:", err)
+		return 2
+	}
+	g := &grep{
+		m:      ahocorasick.NewOf[string](),
+		out:    bufio.NewWriter(stdout),
+		only:   *only,
+		counts: map[string]int{},
+	}
+	defer g.out.Flush()
+	switch *color {
+	case "always":
+		g.color = true
+	case "auto":
+		g.color = isTerminal(stdout)
+	case "never":
+	default:
+		fmt.Fprintf(stderr, "acgrep: unknown -color: %s\n", *color)
+		return 2
+	}
+	if err := g.load(*patFile, *tsv); err != nil {
+		fmt.Fprintln(stderr, "acgrep:", err)
+		return 2
+	}
+	if err := g.m.Compile(opts...); err != nil {
+		fmt.Fprintln(stderr, "acgrep:", err)
+		return 2
+	}
+
+	status := 1
+	scan := g.grep
+	if *count {
+		scan = g.count
+	}
+	names := fs.Args()
+	if len(names) == 0 {
+		if err := scan("(standard input)", stdin); err != nil {
+			fmt.Fprintln(stderr, "acgrep:", err)
+			status = 2
+		}
+	}
+	for _, name := range names {
+		if err := scanFile(name, scan); err != nil {
+			fmt.Fprintln(stderr, "acgrep:", err)
+			status = 2
+		}
+	}
+	if *count {
+		g.printCounts()
+	}
+	if g.found && status != 2 {
+		status = 0
+	}
+	return status
+}
+
+func matchOptions(mode string, ignoreCase, word bool) ([]ahocorasick.Option, error) {
+	var opts []ahocorasick.Option
+	switch mode {
+	case "standard":
+		opts = append(opts, ahocorasick.WithMode(ahocorasick.Standard))
+	case "longest":
+		opts = append(opts, ahocorasick.WithMode(ahocorasick.LeftmostLongest))
+	case "first":
+		opts = append(opts, ahocorasick.WithMode(ahocorasick.LeftmostFirst))
+	default:
+		return nil, fmt.Errorf("unknown -mode: %s", mode)
+	}
+	if ignoreCase {
+		opts = append(opts, ahocorasick.WithFold(ahocorasick.FoldCase))
+	}
+	if word {
+		opts = append(opts, ahocorasick.WithBoundary(ahocorasick.WordBoundary))
+	}
+	return opts, nil
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// load adds patterns in a pattern file to the matcher.
+func (g *grep) load(name string, tsv bool) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if tsv {
+		err = ahocorasick.LoadTSV(g.m, f)
+	} else {
+		err = ahocorasick.LoadLines(g.m, f, ahocorasick.SkipBlank())
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func scanFile(name string, scan func(string, io.Reader) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return scan(name, f)
+}
+
+// grep prints matched lines, or matched parts of them.
+func (g *grep) grep(name string, r io.Reader) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			g.grepLine(name, n, strings.TrimRight(line, "\r\n"))
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func (g *grep) grepLine(name string, n int, line string) {
+	var matches []ahocorasick.MatchOf[string]
+	g.m.Each(line, func(v ahocorasick.MatchOf[string]) bool {
+		matches = append(matches, v)
+		return true
+	})
+	if len(matches) == 0 {
+		return
+	}
+	g.found = true
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Index < matches[j].Index
+	})
+	if !g.only {
+		g.printPrefix(name, n, matches[0].Index, line)
+		g.out.WriteString(g.highlight(line, matches))
+		g.out.WriteByte('\n')
+		return
+	}
+	for _, v := range matches {
+		g.printPrefix(name, n, v.Index, line)
+		g.out.WriteString(g.paint(colorMatch, line[v.Index:v.End]))
+		if v.Value != "" {
+			g.out.WriteString("\t" + v.Value)
+		}
+		g.out.WriteByte('\n')
+	}
+}
+
+// printPrefix prints "file:line:column:" of a match at idx in line.
+func (g *grep) printPrefix(name string, n, idx int, line string) {
+	col := utf8.RuneCountInString(line[:idx]) + 1
+	fmt.Fprintf(g.out, "%s:%s:%d:", g.paint(colorFile, name), g.paint(colorLine, fmt.Sprint(n)), col)
+}
+
+// highlight returns line which has colored matches.  Overlapped matches are
+// colored as one.
+func (g *grep) highlight(line string, matches []ahocorasick.MatchOf[string]) string {
+	if !g.color {
+		return line
+	}
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(matches); {
+		start, end := matches[i].Index, matches[i].End
+		for i++; i < len(matches) && matches[i].Index < end; i++ {
+			end = max(end, matches[i].End)
+		}
+		b.WriteString(line[last:start])
+		b.WriteString(g.paint(colorMatch, line[start:end]))
+		last = end
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+func (g *grep) paint(color, s string) string {
+	if !g.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// count counts matches per pattern in a stream.
+func (g *grep) count(name string, r io.Reader) error {
+	return g.m.MatchReader(r, func(v ahocorasick.MatchOf[string]) bool {
+		g.counts[v.Pattern]++
+		g.found = true
+		return true
+	})
+}
+
+func (g *grep) printCounts() {
+	patterns := make([]string, 0, len(g.counts))
+	for p := range g.counts {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	for _, p := range patterns {
+		fmt.Fprintf(g.out, "%s\t%d\n", p, g.counts[p])
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runGrep(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &out, &errOut)
+	return out.String(), errOut.String(), status
+}
+
+func TestGrep(t *testing.T) {
+	dir := t.TempDir()
+	pats := writeFile(t, dir, "pats.txt", "cat\r\n\ncategory\nネコ\n")
+	text := writeFile(t, dir, "text.txt", "a dog\nネコとcat\r\nmy category\n")
+	for _, c := range []struct {
+		args   []string
+		out    string
+		status int
+	}{
+		{
+			[]string{"-f", pats, text},
+			text + ":2:1:ネコとcat\n" + text + ":3:4:my category\n",
+			0,
+		},
+		{
+			[]string{"-f", pats, "-o", text},
+			text + ":2:1:ネコ\n" + text + ":2:4:cat\n" + text + ":3:4:category\n",
+			0,
+		},
+		{
+			[]string{"-f", pats, "-o", "-mode", "standard", text},
+			text + ":2:1:ネコ\n" + text + ":2:4:cat\n" + text + ":3:4:cat\n" + text + ":3:4:category\n",
+			0,
+		},
+		{
+			[]string{"-f", pats, "-c", text},
+			"cat\t1\ncategory\t1\nネコ\t1\n",
+			0,
+		},
+		{
+			[]string{"-f", pats, "-color", "always", text},
+			"\x1b[35m" + text + "\x1b[0m:\x1b[32m2\x1b[0m:1:\x1b[01;31mネコ\x1b[0mと\x1b[01;31mcat\x1b[0m\n" +
+				"\x1b[35m" + text + "\x1b[0m:\x1b[32m3\x1b[0m:4:my \x1b[01;31mcategory\x1b[0m\n",
+			0,
+		},
+		{
+			[]string{"-f", pats, "-w", "-i", "-o"},
+			"(standard input):1:8:CAT\n",
+			0,
+		},
+		{
+			[]string{"-f", pats, "-w", text},
+			text + ":3:4:my category\n",
+			0,
+		},
+		{
+			[]string{"-f", pats, "-w", "-c"},
+			"",
+			1,
+		},
+	} {
+		out, errOut, status := runGrep(t, "concat CAT\n", c.args...)
+		if out != c.out || status != c.status {
+			t.Errorf("acgrep %q:\n  output: %q (expected %q)\n  status: %d (expected %d)\n  stderr: %s",
+				c.args, out, c.out, status, c.status, errOut)
+		}
+	}
+}
+
+func TestGrepTSV(t *testing.T) {
+	dir := t.TempDir()
+	pats := writeFile(t, dir, "pats.tsv", "secret\tPASSWORD\nkey\n")
+	out, _, status := runGrep(t, "my secret key", "-f", pats, "-tsv", "-o")
+	if exp := "(standard input):1:4:secret\tPASSWORD\n(standard input):1:11:key\n"; out != exp || status != 0 {
+		t.Errorf("unexpected output: %q (status %d)", out, status)
+	}
+
+	pats = writeFile(t, dir, "bad.tsv", "ok\t1\n\tvalue\n")
+	_, errOut, status := runGrep(t, "", "-f", pats, "-tsv")
+	if !strings.Contains(errOut, "bad.tsv: line 2: empty pattern") || status != 2 {
+		t.Errorf("unexpected error: %q (status %d)", errOut, status)
+	}
+}
+
+func TestGrepErrors(t *testing.T) {
+	dir := t.TempDir()
+	pats := writeFile(t, dir, "pats.txt", "cat\n")
+	for _, args := range [][]string{
+		{},
+		{"-f", filepath.Join(dir, "none.txt")},
+		{"-f", pats, "-mode", "unknown"},
+		{"-f", pats, "-color", "sometimes"},
+		{"-f", pats, filepath.Join(dir, "none.txt")},
+		{"-unknown"},
+	} {
+		if _, _, status := runGrep(t, "cat", args...); status != 2 {
+			t.Errorf("acgrep %q returns %d, expected 2", args, status)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package trie
+
+// KeySeqStrings represents KeySeq for a sequence of tokens (strings).
+type KeySeqStrings []string
+
+// Keys implements KeySeq.Keys for a sequence of tokens.
+func (s KeySeqStrings) Keys() []Key {
+	keys := make([]Key, len(s))
+	for i, t := range s {
+		keys[i] = KeyString(t)
+	}
+	return keys
+}
+
+// KeyString represents Key for a token (string).
+type KeyString string
+
+// Compare implements Key.Compare for a token.
+func (s KeyString) Compare(v Key) Order {
+	s2, ok := v.(KeyString)
+	if !ok {
+		return MISMATCH
+	} else if s == s2 {
+		return EQUAL
+	} else if s < s2 {
+		return BEFORE
+	}
+	return AFTER
+}
+
+// KeySeqInts represents KeySeq for a sequence of token IDs (int).
+type KeySeqInts []int
+
+// Keys implements KeySeq.Keys for a sequence of token IDs.
+func (s KeySeqInts) Keys() []Key {
+	keys := make([]Key, len(s))
+	for i, n := range s {
+		keys[i] = KeyInt(n)
+	}
+	return keys
+}
+
+// KeyInt represents Key for a token ID (int).
+type KeyInt int
+
+// Compare implements Key.Compare for a token ID.
+func (n KeyInt) Compare(v Key) Order {
+	n2, ok := v.(KeyInt)
+	if !ok {
+		return MISMATCH
+	} else if n == n2 {
+		return EQUAL
+	} else if n < n2 {
+		return BEFORE
+	}
+	return AFTER
+}
@@ -0,0 +1,33 @@
+package trie
+
+import "testing"
+
+func TestKeySeqStrings(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(KeySeqStrings{"new", "york"}, 1)
+	trie.Put(KeySeqStrings{"new", "jersey"}, 2)
+	if n := Get(trie, KeySeqStrings{"new", "york"}); n == nil || n.Value() != 1 {
+		t.Errorf("unexpected node for [new york]: %v", n)
+	}
+	if n := Get(trie, KeySeqStrings{"new"}); n == nil || n.Value() != nil {
+		t.Errorf("unexpected node for [new]: %v", n)
+	}
+	if n := Get(trie, KeySeqStrings{"york"}); n != nil {
+		t.Errorf("unexpected node for [york]: %v", n)
+	}
+	if o := KeyString("a").Compare(KeyInt(1)); o != MISMATCH {
+		t.Errorf("KeyString and KeyInt should be mismatched: %v", o)
+	}
+}
+
+func TestKeySeqInts(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(KeySeqInts{3, 1, 4}, "pi")
+	trie.Put(KeySeqInts{2, 7}, "e")
+	if n := Get(trie, KeySeqInts{3, 1, 4}); n == nil || n.Value() != "pi" {
+		t.Errorf("unexpected node for [3 1 4]: %v", n)
+	}
+	if n := Get(trie, KeySeqInts{2, 7, 1}); n != nil {
+		t.Errorf("unexpected node for [2 7 1]: %v", n)
+	}
+}